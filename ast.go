@@ -0,0 +1,256 @@
+package gqlfetch
+
+import (
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// introspectionSource is the source of every definition converted from an introspection result,
+// gqlparser's formatter expects definitions to know their source.
+var introspectionSource = &ast.Source{Name: "introspection"}
+
+// oneOfDirective declares @oneOf, which gqlparser's prelude predates, as the builtin it is since
+// the September 2025 specification.
+func oneOfDirective() *ast.DirectiveDefinition {
+	return &ast.DirectiveDefinition{
+		Name:      "oneOf",
+		Locations: []ast.DirectiveLocation{ast.LocationInputObject},
+		Position:  &ast.Position{Src: validator.Prelude},
+	}
+}
+
+// introspectionSchemaToAst validates the introspected schema against the gqlparser prelude,
+// the prelude supplies the builtin scalars and introspection types the server also reports.
+func introspectionSchemaToAst(schema introspectionSchema) (*ast.Schema, error) {
+	doc, err := introspectionSchemaToAstDocument(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	validated, err := validateSchemaDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to validate schema: %w", err)
+	}
+	return validated, nil
+}
+
+// validateSchemaDocument validates doc on top of the gqlparser prelude, definitions doc shares with
+// the prelude are taken from the prelude.
+func validateSchemaDocument(doc *ast.SchemaDocument) (*ast.Schema, error) {
+	prelude, err := parser.ParseSchema(validator.Prelude)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse prelude: %w", err)
+	}
+
+	builtin := make(map[string]bool, len(prelude.Definitions))
+	for _, def := range prelude.Definitions {
+		builtin[def.Name] = true
+	}
+	for _, def := range doc.Definitions {
+		if !builtin[def.Name] {
+			prelude.Definitions = append(prelude.Definitions, def)
+		}
+	}
+	for _, def := range prelude.Directives {
+		builtin["@"+def.Name] = true
+	}
+	for _, def := range doc.Directives {
+		if !builtin["@"+def.Name] {
+			prelude.Directives = append(prelude.Directives, def)
+		}
+	}
+	prelude.Schema = doc.Schema
+	prelude.Extensions = doc.Extensions
+
+	return validator.ValidateSchemaDocument(prelude)
+}
+
+// introspectionSchemaToAstDocument converts the introspected schema as-is, builtins included.
+func introspectionSchemaToAstDocument(schema introspectionSchema) (*ast.SchemaDocument, error) {
+	doc := &ast.SchemaDocument{}
+
+	if schema.QueryType.Name != "" {
+		def := &ast.SchemaDefinition{}
+		def.OperationTypes = append(def.OperationTypes, &ast.OperationTypeDefinition{Operation: ast.Query, Type: schema.QueryType.Name})
+		if schema.MutationType.Name != "" {
+			def.OperationTypes = append(def.OperationTypes, &ast.OperationTypeDefinition{Operation: ast.Mutation, Type: schema.MutationType.Name})
+		}
+		if schema.SubscriptionType.Name != "" {
+			def.OperationTypes = append(def.OperationTypes, &ast.OperationTypeDefinition{Operation: ast.Subscription, Type: schema.SubscriptionType.Name})
+		}
+		doc.Schema = append(doc.Schema, def)
+	}
+
+	for _, directive := range schema.Directives {
+		def := &ast.DirectiveDefinition{
+			Name:         directive.Name,
+			Description:  directive.Description,
+			Locations:    directive.Locations,
+			IsRepeatable: directive.IsRepeatable,
+			Position:     &ast.Position{Src: introspectionSource},
+		}
+		for _, arg := range directive.Args {
+			argDef, err := introspectionInputFieldToAstArgument(arg, fmt.Sprintf("@%s.%s", directive.Name, arg.Name))
+			if err != nil {
+				return nil, err
+			}
+			def.Arguments = append(def.Arguments, argDef)
+		}
+		doc.Directives = append(doc.Directives, def)
+	}
+
+	oneOf := false
+	for _, typ := range schema.Types {
+		def, err := introspectionTypeDefinitionToAst(typ)
+		if err != nil {
+			return nil, err
+		}
+		doc.Definitions = append(doc.Definitions, def)
+		oneOf = oneOf || typ.IsOneOf
+	}
+
+	if oneOf && doc.Directives.ForName("oneOf") == nil {
+		doc.Directives = append(doc.Directives, oneOfDirective())
+	}
+
+	return doc, nil
+}
+
+func introspectionTypeDefinitionToAst(typ introspectionTypeDefinition) (*ast.Definition, error) {
+	def := &ast.Definition{
+		Kind:        typ.Kind,
+		Name:        typ.Name,
+		Description: typ.Description,
+		Position:    &ast.Position{Src: introspectionSource},
+	}
+
+	switch typ.Kind {
+	case ast.Object, ast.Interface:
+		for _, intface := range typ.Interfaces {
+			def.Interfaces = append(def.Interfaces, intface.Name)
+		}
+		for _, field := range typ.Fields {
+			fieldType, err := introspectionTypeToAstType(field.Type, fmt.Sprintf("%s.%s", typ.Name, field.Name))
+			if err != nil {
+				return nil, err
+			}
+			fieldDef := &ast.FieldDefinition{
+				Name:        field.Name,
+				Description: field.Description,
+				Type:        fieldType,
+				Directives:  deprecatedDirectives(field.IsDeprecated, field.DeprecationReason),
+			}
+			for _, arg := range field.Args {
+				argDef, err := introspectionInputFieldToAstArgument(arg, fmt.Sprintf("%s.%s.%s", typ.Name, field.Name, arg.Name))
+				if err != nil {
+					return nil, err
+				}
+				fieldDef.Arguments = append(fieldDef.Arguments, argDef)
+			}
+			def.Fields = append(def.Fields, fieldDef)
+		}
+
+	case ast.Union:
+		for _, possibleType := range typ.PossibleTypes {
+			member, err := introspectionTypeToAstType(possibleType, typ.Name)
+			if err != nil {
+				return nil, err
+			}
+			def.Types = append(def.Types, member.Name())
+		}
+
+	case ast.Enum:
+		for _, value := range typ.EnumValues {
+			def.EnumValues = append(def.EnumValues, &ast.EnumValueDefinition{
+				Name:        value.Name,
+				Description: value.Description,
+				Directives:  deprecatedDirectives(value.IsDeprecated, value.DeprecationReason),
+			})
+		}
+
+	case ast.InputObject:
+		for _, field := range typ.InputFields {
+			argDef, err := introspectionInputFieldToAstArgument(field, fmt.Sprintf("%s.%s", typ.Name, field.Name))
+			if err != nil {
+				return nil, err
+			}
+			def.Fields = append(def.Fields, &ast.FieldDefinition{
+				Name:         argDef.Name,
+				Description:  argDef.Description,
+				Type:         argDef.Type,
+				DefaultValue: argDef.DefaultValue,
+				Directives:   argDef.Directives,
+			})
+		}
+		if typ.IsOneOf {
+			def.Directives = append(def.Directives, &ast.Directive{Name: "oneOf"})
+		}
+
+	case ast.Scalar:
+		if typ.SpecifiedByURL != nil {
+			def.Directives = append(def.Directives, &ast.Directive{
+				Name: "specifiedBy",
+				Arguments: ast.ArgumentList{{
+					Name:  "url",
+					Value: &ast.Value{Kind: ast.StringValue, Raw: *typ.SpecifiedByURL},
+				}},
+			})
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported type for %s: %s", typ.Name, typ.Kind)
+	}
+
+	return def, nil
+}
+
+func introspectionInputFieldToAstArgument(field introspectionInputField, path string) (*ast.ArgumentDefinition, error) {
+	fieldType, err := introspectionTypeToAstType(field.Type, path)
+	if err != nil {
+		return nil, err
+	}
+	def := &ast.ArgumentDefinition{
+		Name:        field.Name,
+		Description: field.Description,
+		Type:        fieldType,
+		Directives:  deprecatedDirectives(field.IsDeprecated, field.DeprecationReason),
+	}
+	if literal, ok := field.DefaultValue.(string); ok {
+		value, err := parseValueLiteral(literal)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse default value %q of %s: %w", literal, path, err)
+		}
+		def.DefaultValue = value
+	}
+	return def, nil
+}
+
+// parseValueLiteral parses a GraphQL value literal, as reported by introspection default values,
+// by wrapping it in a throwaway input definition since gqlparser exposes no standalone value parser.
+func parseValueLiteral(literal string) (*ast.Value, error) {
+	doc, err := parser.ParseSchema(&ast.Source{Input: "input Literal { value: String = " + literal + " }"})
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Definitions) != 1 || len(doc.Definitions[0].Fields) != 1 {
+		return nil, fmt.Errorf("not a single value literal")
+	}
+	return doc.Definitions[0].Fields[0].DefaultValue, nil
+}
+
+func deprecatedDirectives(isDeprecated bool, deprecationReason interface{}) ast.DirectiveList {
+	if !isDeprecated {
+		return nil
+	}
+	directive := &ast.Directive{Name: "deprecated"}
+	if reason, ok := deprecationReason.(string); ok && reason != "" {
+		directive.Arguments = ast.ArgumentList{{
+			Name:  "reason",
+			Value: &ast.Value{Kind: ast.StringValue, Raw: reason},
+		}}
+	}
+	return ast.DirectiveList{directive}
+}
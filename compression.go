@@ -0,0 +1,123 @@
+package gqlfetch
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding is advertised on every request. Setting it ourselves disables the transparent gzip
+// support of http.Transport, so responses are always decoded by decodeContentEncoding.
+const acceptEncoding = "gzip, deflate, br"
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// decodeContentEncoding unwraps the response body according to the Content-Encoding header,
+// encodings are listed in the order they were applied so they are removed in reverse.
+func decodeContentEncoding(body io.ReadCloser, contentEncoding string) (io.ReadCloser, error) {
+	if contentEncoding == "" {
+		return body, nil
+	}
+
+	encodings := strings.Split(contentEncoding, ",")
+	var reader io.Reader = body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(reader)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode gzip response: %w", err)
+			}
+			reader = gz
+		case "deflate":
+			// HTTP deflate is zlib wrapped, yet some servers send raw deflate streams.
+			buffered := bufio.NewReader(reader)
+			header, err := buffered.Peek(2)
+			if err == nil && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 && header[0]&0x0f == 8 {
+				zr, err := zlib.NewReader(buffered)
+				if err != nil {
+					return nil, fmt.Errorf("unable to decode deflate response: %w", err)
+				}
+				reader = zr
+			} else {
+				reader = flate.NewReader(buffered)
+			}
+		case "br":
+			reader = brotli.NewReader(reader)
+		default:
+			return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+		}
+	}
+
+	return readCloser{Reader: reader, Closer: body}, nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// downloadStats reports the size and duration of a download to the debug writer once the body is closed.
+type downloadStats struct {
+	io.Reader
+	body     io.Closer
+	wire     *countingReader
+	decoded  *countingReader
+	encoding string
+	start    time.Time
+	debug    io.Writer
+}
+
+func newDownloadStats(res io.ReadCloser, wire *countingReader, encoding string, start time.Time, debug io.Writer) *downloadStats {
+	decoded := &countingReader{reader: res}
+	return &downloadStats{
+		Reader:   decoded,
+		body:     res,
+		wire:     wire,
+		decoded:  decoded,
+		encoding: encoding,
+		start:    start,
+		debug:    debug,
+	}
+}
+
+func (s *downloadStats) Close() error {
+	err := s.body.Close()
+	if s.encoding == "" {
+		fmt.Fprintf(s.debug, "downloaded %s in %s\n", formatBytes(s.decoded.n), time.Since(s.start).Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(s.debug, "downloaded %s (%s %s on the wire) in %s\n",
+			formatBytes(s.decoded.n), formatBytes(s.wire.n), s.encoding, time.Since(s.start).Round(time.Millisecond))
+	}
+	return err
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,95 @@
+package gqlfetch
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestBuildClientSchemaWithOptionsCompression(t *testing.T) {
+	const response = `{"data": {"__schema": {"queryType": {"name": "Query"}, "types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [{"name": "ping", "args": [], "type": {"kind": "SCALAR", "name": "String"}}]}
+	], "directives": []}}}`
+
+	tests := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"deflate, gzip": func(w io.Writer) io.WriteCloser {
+			gz := gzip.NewWriter(w)
+			return &stackedWriter{WriteCloser: zlib.NewWriter(gz), outer: gz}
+		},
+	}
+
+	for encoding, encoder := range tests {
+		t.Run(encoding, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if accept := r.Header.Get("Accept-Encoding"); accept != acceptEncoding {
+					t.Errorf("Accept-Encoding = %q, want %q", accept, acceptEncoding)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", encoding)
+				enc := encoder(w)
+				enc.Write([]byte(response))
+				enc.Close()
+			}))
+			defer server.Close()
+
+			// Disabling compression on the transport proves decoding does not rely on it.
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			debug := &strings.Builder{}
+			schema, err := BuildClientSchemaWithOptions(context.Background(), BuildClientSchemaOptions{
+				Endpoint:   server.URL,
+				Method:     http.MethodPost,
+				HTTPClient: client,
+				Debug:      debug,
+			})
+			if err != nil {
+				t.Fatalf("BuildClientSchemaWithOptions() error = %v", err)
+			}
+			if !strings.Contains(schema, "ping: String") {
+				t.Errorf("expected schema to contain ping field, got %s", schema)
+			}
+			if !strings.Contains(debug.String(), encoding+" on the wire") {
+				t.Errorf("expected debug output to report the download, got %q", debug.String())
+			}
+		})
+	}
+}
+
+func Test_decodeContentEncodingRawDeflate(t *testing.T) {
+	compressed := &bytes.Buffer{}
+	fw, _ := flate.NewWriter(compressed, flate.DefaultCompression)
+	fw.Write([]byte(`{"data": null}`))
+	fw.Close()
+
+	body, err := decodeContentEncoding(io.NopCloser(compressed), "deflate")
+	if err != nil {
+		t.Fatalf("decodeContentEncoding() error = %v", err)
+	}
+	got, err := io.ReadAll(body)
+	if err != nil || string(got) != `{"data": null}` {
+		t.Errorf("decodeContentEncoding() = %q, %v", got, err)
+	}
+}
+
+type stackedWriter struct {
+	io.WriteCloser
+	outer io.Closer
+}
+
+func (w *stackedWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return w.outer.Close()
+}
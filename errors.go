@@ -0,0 +1,122 @@
+package gqlfetch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MalformedTypeRefError is returned when an introspected type reference cannot be converted,
+// Path locates the reference, e.g. "User.friends.first" for an argument or "@tag.name" for a directive argument.
+type MalformedTypeRefError struct {
+	Path   string
+	Kind   string
+	Reason string
+}
+
+func (e *MalformedTypeRefError) Error() string {
+	if e.Kind != "" {
+		return fmt.Sprintf("malformed type reference at %s (%s): %s", e.Path, e.Kind, e.Reason)
+	}
+	return fmt.Sprintf("malformed type reference at %s: %s", e.Path, e.Reason)
+}
+
+// GraphQLError is a single entry of the errors list of a GraphQL response, kept as the server sent it.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLErrorLocation `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type GraphQLErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Code returns extensions.code, the conventional machine readable error classification, if present.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// GraphQLErrors is returned whenever the introspection response carries errors, including partial
+// responses where data is present alongside them.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	var errs []string
+	for _, err := range e {
+		errs = append(errs, err.Message)
+	}
+	return "encountered the following GraphQL errors: " + strings.Join(errs, ",")
+}
+
+// HTTPError is returned when the endpoint answers with a non 200 status, GraphQL errors are
+// decoded from JSON error bodies as allowed by the GraphQL-over-HTTP specification.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// Body holds at most the first 4KiB of the response body.
+	Body   string
+	Errors GraphQLErrors
+}
+
+func (e *HTTPError) Error() string {
+	if len(e.Errors) != 0 {
+		return fmt.Sprintf("unable to download schema: %s: %v", e.Status, e.Errors)
+	}
+	if body := strings.TrimSpace(e.Body); body != "" {
+		return fmt.Sprintf("unable to download schema: %s: %s", e.Status, body)
+	}
+	return fmt.Sprintf("unable to download schema: %s", e.Status)
+}
+
+// Unwrap exposes the decoded GraphQL errors to errors.As.
+func (e *HTTPError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors
+}
+
+// ContentTypeError is returned when the endpoint answers with something other than JSON,
+// typically an HTML login or error page served in place of the GraphQL endpoint.
+type ContentTypeError struct {
+	ContentType string
+	// Body holds at most the first 4KiB of the response body.
+	Body string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf(
+		"unable to download schema: unexpected content type %q, expected application/graphql-response+json or application/json",
+		e.ContentType,
+	)
+}
+
+// InvalidSchemaError is returned when the Validate option finds the printed schema does not load,
+// Line and Column locate the problem within the printed SDL.
+type InvalidSchemaError struct {
+	Message string
+	Line    int
+	Column  int
+	Err     error
+}
+
+func (e *InvalidSchemaError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("printed schema is invalid: %s", e.Message)
+	}
+	return fmt.Sprintf("printed schema is invalid at line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// Unwrap exposes the underlying gqlparser error.
+func (e *InvalidSchemaError) Unwrap() error {
+	return e.Err
+}
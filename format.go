@@ -0,0 +1,52 @@
+package gqlfetch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DescriptionStyle selects how single-line descriptions are printed, multi-line descriptions are
+// always printed as block strings on their own lines.
+type DescriptionStyle string
+
+const (
+	// DescriptionBlock prints the block string quotes on their own lines, the default.
+	DescriptionBlock DescriptionStyle = "block"
+	// DescriptionCompact prints `"""description"""` on a single line, as graphql-js does.
+	DescriptionCompact DescriptionStyle = "compact"
+	// DescriptionString prints a regular `"description"` string.
+	DescriptionString DescriptionStyle = "string"
+)
+
+// PrintOptions controls the layout of the printed schema, the zero value prints the classic layout
+// of tab indentation and one argument per line.
+type PrintOptions struct {
+	// Indent defaults to a tab.
+	Indent string
+	// InlineArgs prints the arguments of a field or directive on its own line when the argument
+	// list is shorter than this many characters and none of the arguments carries a description.
+	// Zero puts every argument on its own line.
+	InlineArgs int
+	// Descriptions defaults to DescriptionBlock.
+	Descriptions DescriptionStyle
+	// TrailingNewline ends the schema with a single newline, instead of the blank line that
+	// follows every definition.
+	TrailingNewline bool
+}
+
+func (o PrintOptions) validate() error {
+	switch o.Descriptions {
+	case "", DescriptionBlock, DescriptionCompact, DescriptionString:
+		return nil
+	default:
+		return fmt.Errorf("unsupported description style: %s", o.Descriptions)
+	}
+}
+
+func (o PrintOptions) indent(depth int) string {
+	indent := o.Indent
+	if indent == "" {
+		indent = "\t"
+	}
+	return strings.Repeat(indent, depth)
+}
@@ -0,0 +1,69 @@
+package gqlfetch
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_decodeAndPrintSchemaPrintOptions(t *testing.T) {
+	const response = `{"data": {"__schema": {
+		"queryType": {"name": "Root"},
+		"directives": [{"name": "auth", "locations": ["FIELD_DEFINITION"], "args": [
+			{"name": "role", "type": {"kind": "ENUM", "name": "Role"}, "defaultValue": "ADMIN"}
+		]}],
+		"types": [
+			{"kind": "OBJECT", "name": "Root", "description": "The root", "interfaces": [], "fields": [
+				{"name": "users", "description": "All users\nPaginated", "args": [
+					{"name": "first", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "10"},
+					{"name": "after", "type": {"kind": "SCALAR", "name": "String"}}
+				], "type": {"kind": "SCALAR", "name": "String"}}
+			]},
+			{"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN", "description": "Says \"hi\""}]}
+		]
+	}}}`
+
+	tests := map[string]struct {
+		options PrintOptions
+		expect  string
+	}{
+		"defaults": {
+			expect: "schema {\n\tquery: Root\n}\n\n" +
+				"directive @auth(\n\trole: Role = ADMIN\n) on FIELD_DEFINITION\n\n" +
+				"\"\"\"\nThe root\n\"\"\"\ntype Root {\n\t\"\"\"\n\tAll users\n\tPaginated\n\t\"\"\"\n\tusers(\n\t\tfirst: Int = 10\n\t\tafter: String\n\t): String\n}\n\n" +
+				"enum Role {\n\t\"\"\"\n\tSays \"hi\"\n\t\"\"\"\n\tADMIN\n}\n\n",
+		},
+		"prettier": {
+			options: PrintOptions{Indent: "  ", InlineArgs: 80, Descriptions: DescriptionString, TrailingNewline: true},
+			expect: "schema {\n  query: Root\n}\n\n" +
+				"directive @auth(role: Role = ADMIN) on FIELD_DEFINITION\n\n" +
+				"\"The root\"\ntype Root {\n  \"\"\"\n  All users\n  Paginated\n  \"\"\"\n  users(first: Int = 10, after: String): String\n}\n\n" +
+				"enum Role {\n  \"Says \\\"hi\\\"\"\n  ADMIN\n}\n",
+		},
+		"compact descriptions and narrow inline args": {
+			options: PrintOptions{InlineArgs: 24, Descriptions: DescriptionCompact},
+			expect: "schema {\n\tquery: Root\n}\n\n" +
+				"directive @auth(role: Role = ADMIN) on FIELD_DEFINITION\n\n" +
+				"\"\"\"The root\"\"\"\ntype Root {\n\t\"\"\"\n\tAll users\n\tPaginated\n\t\"\"\"\n\tusers(\n\t\tfirst: Int = 10\n\t\tafter: String\n\t): String\n}\n\n" +
+				"enum Role {\n\t\"\"\"\n\tSays \"hi\"\n\t\"\"\"\n\tADMIN\n}\n\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeAndPrintSchema(strings.NewReader(response), BuildClientSchemaOptions{Print: tt.options, Validate: true})
+			if err != nil {
+				t.Fatalf("decodeAndPrintSchema() error = %v", err)
+			}
+			if got != tt.expect {
+				t.Errorf("decodeAndPrintSchema() = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
+
+func Test_decodeAndPrintSchemaUnsupportedDescriptionStyle(t *testing.T) {
+	_, err := decodeAndPrintSchema(strings.NewReader(`{}`), BuildClientSchemaOptions{Print: PrintOptions{Descriptions: "fancy"}})
+	if err == nil || err.Error() != "unsupported description style: fancy" {
+		t.Errorf("decodeAndPrintSchema() error = %v, want unsupported description style", err)
+	}
+}
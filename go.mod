@@ -2,4 +2,9 @@ module github.com/suessflorian/gqlfetch
 
 go 1.17
 
-require github.com/vektah/gqlparser/v2 v2.5.16
+require (
+	github.com/andybalholm/brotli v1.1.0
+	github.com/vektah/gqlparser/v2 v2.5.16
+)
+
+require github.com/agnivade/levenshtein v1.1.1 // indirect
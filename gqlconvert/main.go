@@ -4,26 +4,122 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 
 	"github.com/suessflorian/gqlfetch"
+	"github.com/suessflorian/gqlfetch/internal/atomicfile"
 )
 
 
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run converts the introspection result, or SDL with --to json, as instructed by the command line
+// arguments. --file - reads stdin.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("gqlconvert", flag.ExitOnError)
+	flags.SetOutput(stderr)
 	var filePath string
 	var withoutBuiltins bool
+	var output string
+	var to string
+	var sort gqlfetch.SortOptions
+	var validate bool
+	var printOptions gqlfetch.PrintOptions
+	var descriptions string
+	var renderer string
+
+	flags.BoolVar(&withoutBuiltins, "without-builtins", false, "Do not include builtin types")
+	flags.StringVar(&filePath, "file", "schema.json", "Path to introspection file as json, or SDL file with --to json, - reads stdin")
+	flags.StringVar(&output, "output", "", "Write the schema to this file instead of stdout, replacing it atomically")
+	flags.StringVar(&output, "o", "", "Shorthand for --output")
+	flags.BoolVar(&sort.Types, "sort", false, "Print types and directives alphabetically")
+	flags.BoolVar(&sort.Fields, "sort-fields", false, "Print fields, arguments, enum values and union members alphabetically")
+	flags.BoolVar(&sort.GroupByKind, "group-by-kind", false, "Print types grouped by kind")
+	flags.StringVar(&printOptions.Indent, "indent", "\t", "Indentation of fields and arguments, e.g. two spaces")
+	flags.IntVar(&printOptions.InlineArgs, "inline-args", 0, "Print arguments on the field's line when they fit in this many characters")
+	flags.StringVar(&descriptions, "descriptions", string(gqlfetch.DescriptionBlock), "Single-line description style (block, compact, string)")
+	flags.BoolVar(&printOptions.TrailingNewline, "trailing-newline", false, "End the schema with a single newline instead of a blank line")
+	flags.StringVar(&renderer, "renderer", string(gqlfetch.RendererPrinter), "Schema renderer, formatter matches the output of gqlgen and other gqlparser based tools (printer, formatter)")
+	flags.BoolVar(&validate, "validate", false, "Check the printed schema loads with gqlparser before writing it")
+	flags.StringVar(&to, "to", "sdl", "Output format, sdl converts introspection json to SDL, json converts SDL to introspection json")
+	flags.Parse(args)
+	printOptions.Descriptions = gqlfetch.DescriptionStyle(descriptions)
+
+	switch to {
+	case "sdl":
+	case "json":
+		return convertToJSON(ctx, filePath, output, stdin, stdout)
+	default:
+		return fmt.Errorf("unsupported output format: %s", to)
+	}
+
+	options := gqlfetch.BuildClientSchemaOptions{
+		WithoutBuiltins: withoutBuiltins,
+		Sort:            sort,
+		Print:           printOptions,
+		Renderer:        gqlfetch.Renderer(renderer),
+		Validate:        validate,
+	}
+	input := stdin
+	if filePath != "-" {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	if output != "" {
+		return atomicfile.Write(output, func(w io.Writer) error {
+			return gqlfetch.WriteClientSchemaFromReader(ctx, w, input, options)
+		})
+	}
 
-	flag.BoolVar(&withoutBuiltins, "without-builtins", false, "Do not include builtin types")
-	flag.StringVar(&filePath, "file", "schema.json", "Path to introspection file as json")
-	flag.Parse()
+	schema, err := gqlfetch.BuildClientSchemaFromReader(ctx, input, options)
+	if err != nil {
+		return err
+	}
+	if printOptions.TrailingNewline {
+		fmt.Fprint(stdout, schema)
+	} else {
+		fmt.Fprintln(stdout, schema)
+	}
+	return nil
+}
 
-	schema, err := gqlfetch.BuildClientSchemaFromFile(ctx, filePath, withoutBuiltins)
+// convertToJSON writes the introspection result of the SDL stored at filePath.
+func convertToJSON(ctx context.Context, filePath, output string, stdin io.Reader, stdout io.Writer) error {
+	var sdl []byte
+	var err error
+	if filePath == "-" {
+		sdl, err = io.ReadAll(stdin)
+	} else {
+		sdl, err = os.ReadFile(filePath)
+	}
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	introspection, err := gqlfetch.BuildIntrospectionFromSDL(ctx, string(sdl))
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Fprintln(stdout, string(introspection))
+		return nil
 	}
-	fmt.Println(schema)
-}
\ No newline at end of file
+	return atomicfile.Write(output, func(w io.Writer) error {
+		_, err := w.Write(append(introspection, '\n'))
+		return err
+	})
+}
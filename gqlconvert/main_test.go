@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunOutput(t *testing.T) {
+	fixture := filepath.Join("..", "testdata", "starwars.json")
+	introspection, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := os.ReadFile(filepath.Join("..", "testdata", "starwars.graphql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		args  []string
+		stdin []byte
+	}{
+		"file": {
+			args: []string{"--file", fixture},
+		},
+		"stdin": {
+			args:  []string{"--file", "-"},
+			stdin: introspection,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "schema.graphql")
+			stdout := &strings.Builder{}
+			args := append(tt.args, "--without-builtins", "-o", path)
+			if err := run(context.Background(), args, bytes.NewReader(tt.stdin), stdout, &strings.Builder{}); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(expect) {
+				t.Errorf("run() wrote %q, want %q", got, expect)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("run() printed %q alongside --output", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunOutputJSON(t *testing.T) {
+	schema := filepath.Join("..", "testdata", "starwars.graphql")
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := run(context.Background(), []string{"--to", "json", "--file", schema, "--output", path}, nil, &strings.Builder{}, &strings.Builder{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	stdout := &strings.Builder{}
+	if err := run(context.Background(), []string{"--file", path, "--without-builtins"}, nil, stdout, &strings.Builder{}); err != nil {
+		t.Fatalf("run() of the written introspection error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "type Query {") {
+		t.Errorf("converting the written introspection back printed %q", stdout.String())
+	}
+}
+
+func TestRunOutputFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.graphql")
+	if err := os.WriteFile(path, []byte("type Query"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := run(context.Background(), []string{"--file", "-", "-o", path}, strings.NewReader(`{"data": {"__schema": {"types": [`), &strings.Builder{}, &strings.Builder{})
+	if err == nil {
+		t.Fatal("run() expected an error for the truncated introspection")
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "type Query" {
+		t.Errorf("run() replaced the existing output with %q", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("run() left %d files behind, want only the existing output", len(entries))
+	}
+}
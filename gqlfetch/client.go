@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+type clientOptions struct {
+	timeout  time.Duration
+	proxy    string
+	caCert   string
+	cert     string
+	key      string
+	insecure bool
+}
+
+func newHTTPClient(options clientOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if options.proxy != "" {
+		proxy, err := url.Parse(options.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.insecure}
+	if options.caCert != "" {
+		pem, err := os.ReadFile(options.caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", options.caCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if (options.cert == "") != (options.key == "") {
+		return nil, errors.New("--cert and --key must be provided together")
+	}
+	if options.cert != "" {
+		cert, err := tls.LoadX509KeyPair(options.cert, options.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Timeout: options.timeout, Transport: transport}, nil
+}
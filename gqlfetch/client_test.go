@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_newHTTPClient(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		options   clientOptions
+		expectErr string
+	}{
+		"cert without key": {
+			options:   clientOptions{cert: "client.pem"},
+			expectErr: "--cert and --key must be provided together",
+		},
+		"key without cert": {
+			options:   clientOptions{key: "client.key"},
+			expectErr: "--cert and --key must be provided together",
+		},
+		"invalid proxy": {
+			options:   clientOptions{proxy: "http://proxy:port"},
+			expectErr: "invalid proxy url",
+		},
+		"missing ca certificate": {
+			options:   clientOptions{caCert: filepath.Join(dir, "missing.pem")},
+			expectErr: "failed to read ca certificate",
+		},
+		"ca file without certificates": {
+			options:   clientOptions{caCert: notPEM},
+			expectErr: "no certificates found in " + notPEM,
+		},
+		"unreadable client certificate": {
+			options:   clientOptions{cert: notPEM, key: notPEM},
+			expectErr: "failed to load client certificate",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := newHTTPClient(tt.options)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expectErr) {
+				t.Errorf("newHTTPClient() error = %v, want %s", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func Test_newHTTPClientCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	untrusted, err := newHTTPClient(clientOptions{timeout: time.Second})
+	if err != nil {
+		t.Fatalf("newHTTPClient() error = %v", err)
+	}
+	if _, err := untrusted.Get(server.URL); err == nil {
+		t.Error("expected the server certificate to be rejected without --cacert")
+	}
+
+	for name, options := range map[string]clientOptions{
+		"cacert":   {timeout: time.Second, caCert: caCert},
+		"insecure": {timeout: time.Second, insecure: true},
+	} {
+		client, err := newHTTPClient(options)
+		if err != nil {
+			t.Fatalf("%s: newHTTPClient() error = %v", name, err)
+		}
+		if client.Timeout != time.Second {
+			t.Errorf("%s: client timeout = %s, want 1s", name, client.Timeout)
+		}
+		res, err := client.Get(server.URL)
+		if err != nil {
+			t.Errorf("%s: request error = %v", name, err)
+			continue
+		}
+		res.Body.Close()
+	}
+}
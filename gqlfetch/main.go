@@ -5,12 +5,15 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/suessflorian/gqlfetch"
+	"github.com/suessflorian/gqlfetch/internal/atomicfile"
 )
 
 const DEFAULT_ENDPOINT = "http://localhost:8080/query"
@@ -18,20 +21,110 @@ const DEFAULT_ENDPOINT = "http://localhost:8080/query"
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run fetches the schema as instructed by the command line arguments, progress and diagnostics
+// go to stderr.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("gqlfetch", flag.ExitOnError)
+	flags.SetOutput(stderr)
 	var endpoint string
+	var method string
 	var withoutBuiltins bool
+	var specVersion string
+	var probe bool
+	var client clientOptions
+	var retry gqlfetch.RetryPolicy
+	var retries int
+	var wait time.Duration
+	var debug bool
+	var output string
+	var sort gqlfetch.SortOptions
+	var validate bool
+	var printOptions gqlfetch.PrintOptions
+	var descriptions string
+	var renderer string
 	headers := make(headers)
 
-	flag.StringVar(&endpoint, "endpoint", DEFAULT_ENDPOINT, "GraphQL server endpoint")
-	flag.Var(&headers, "header", "Headers to be passed endpoint (can appear multiple times)")
-	flag.BoolVar(&withoutBuiltins, "without-builtins", false, "Do not include builtin types")
-	flag.Parse()
+	flags.StringVar(&endpoint, "endpoint", DEFAULT_ENDPOINT, "GraphQL server endpoint")
+	flags.StringVar(&method, "method", http.MethodPost, "HTTP method, GET sends the query as URL query parameters")
+	flags.Var(&headers, "header", "Headers to be passed endpoint (can appear multiple times)")
+	flags.BoolVar(&withoutBuiltins, "without-builtins", false, "Do not include builtin types")
+	flags.StringVar(&specVersion, "spec", string(gqlfetch.SpecJune2018), "GraphQL specification edition to introspect (June2018, October2021, September2025)")
+	flags.BoolVar(&probe, "probe", false, "Discover the introspection fields the server supports, overrides --spec")
+	flags.DurationVar(&client.timeout, "timeout", 2*time.Minute, "Timeout for each request to the endpoint")
+	flags.StringVar(&client.proxy, "proxy", "", "Proxy url, defaults to the HTTP_PROXY/HTTPS_PROXY environment")
+	flags.StringVar(&client.caCert, "cacert", "", "PEM file of certificate authorities to trust")
+	flags.StringVar(&client.cert, "cert", "", "PEM client certificate for mutual TLS")
+	flags.StringVar(&client.key, "key", "", "PEM client private key for mutual TLS")
+	flags.BoolVar(&client.insecure, "insecure", false, "Skip TLS certificate verification")
+	flags.IntVar(&retries, "retries", 0, "Number of times to retry connection errors and 408/429/502/503/504 responses")
+	flags.DurationVar(&retry.Wait, "retry-wait", time.Second, "Backoff before the first retry, doubling with every retry")
+	flags.DurationVar(&wait, "wait", 0, "Poll the endpoint every --retry-wait until introspection succeeds or this much time has passed")
+	flags.BoolVar(&debug, "debug", false, "Report requests and download size/time on stderr")
+	flags.StringVar(&output, "output", "", "Write the schema to this file instead of stdout, replacing it atomically")
+	flags.StringVar(&output, "o", "", "Shorthand for --output")
+	flags.BoolVar(&sort.Types, "sort", false, "Print types and directives alphabetically")
+	flags.BoolVar(&sort.Fields, "sort-fields", false, "Print fields, arguments, enum values and union members alphabetically")
+	flags.BoolVar(&sort.GroupByKind, "group-by-kind", false, "Print types grouped by kind")
+	flags.StringVar(&printOptions.Indent, "indent", "\t", "Indentation of fields and arguments, e.g. two spaces")
+	flags.IntVar(&printOptions.InlineArgs, "inline-args", 0, "Print arguments on the field's line when they fit in this many characters")
+	flags.StringVar(&descriptions, "descriptions", string(gqlfetch.DescriptionBlock), "Single-line description style (block, compact, string)")
+	flags.BoolVar(&printOptions.TrailingNewline, "trailing-newline", false, "End the schema with a single newline instead of a blank line")
+	flags.StringVar(&renderer, "renderer", string(gqlfetch.RendererPrinter), "Schema renderer, formatter matches the output of gqlgen and other gqlparser based tools (printer, formatter)")
+	flags.BoolVar(&validate, "validate", false, "Check the printed schema loads with gqlparser before writing it")
+	flags.Parse(args)
+	printOptions.Descriptions = gqlfetch.DescriptionStyle(descriptions)
+	retry.MaxAttempts = retries + 1
 
-	schema, err := gqlfetch.BuildClientSchemaWithHeaders(ctx, endpoint, http.Header(headers), withoutBuiltins)
+	httpClient, err := newHTTPClient(client)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	options := gqlfetch.BuildClientSchemaOptions{
+		Endpoint:        endpoint,
+		Method:          strings.ToUpper(method),
+		Headers:         http.Header(headers),
+		WithoutBuiltins: withoutBuiltins,
+		SpecVersion:     gqlfetch.SpecVersion(specVersion),
+		Probe:           probe,
+		HTTPClient:      httpClient,
+		Retry:           retry,
+		Sort:            sort,
+		Print:           printOptions,
+		Renderer:        gqlfetch.Renderer(renderer),
+		Validate:        validate,
+	}
+	if debug {
+		options.Debug = stderr
 	}
-	fmt.Println(schema)
+
+	var schema string
+	err = waitFor(ctx, wait, retry.Wait, stderr, func(ctx context.Context) error {
+		if output != "" {
+			return atomicfile.Write(output, func(w io.Writer) error {
+				return gqlfetch.WriteClientSchema(ctx, w, options)
+			})
+		}
+		schema, err = gqlfetch.BuildClientSchemaWithOptions(ctx, options)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		if printOptions.TrailingNewline {
+			fmt.Fprint(stdout, schema)
+		} else {
+			fmt.Fprintln(stdout, schema)
+		}
+	}
+	return nil
 }
 
 type headers map[string][]string
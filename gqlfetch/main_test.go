@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunOutput(t *testing.T) {
+	introspection, err := os.ReadFile(filepath.Join("..", "testdata", "starwars.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := os.ReadFile(filepath.Join("..", "testdata", "starwars.graphql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(introspection)
+	}))
+	defer server.Close()
+
+	t.Run("stdout", func(t *testing.T) {
+		stdout := &strings.Builder{}
+		err := run(context.Background(), []string{"--endpoint", server.URL, "--header", "Authorization=Bearer token", "--without-builtins"}, stdout, &strings.Builder{})
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if got := stdout.String(); got != string(expect)+"\n" {
+			t.Errorf("run() printed %q, want %q", got, string(expect)+"\n")
+		}
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "schema.graphql")
+		stdout := &strings.Builder{}
+		err := run(context.Background(), []string{"--endpoint", server.URL, "--header", "Authorization=Bearer token", "--without-builtins", "-o", path}, stdout, &strings.Builder{})
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(expect) {
+			t.Errorf("run() wrote %q, want %q", got, expect)
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("run() printed %q alongside --output", stdout.String())
+		}
+	})
+
+	t.Run("failure keeps existing output", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "schema.graphql")
+		if err := os.WriteFile(path, []byte("type Query"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		err := run(context.Background(), []string{"--endpoint", server.URL, "--output", path}, &strings.Builder{}, &strings.Builder{})
+		if err == nil {
+			t.Fatal("run() expected an error for the unauthorized request")
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "type Query" {
+			t.Errorf("run() replaced the existing output with %q", got)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("run() left %d files behind, want only the existing output", len(entries))
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/suessflorian/gqlfetch"
+)
+
+// waitFor keeps calling fetch until it succeeds or the wait deadline expires, reporting every
+// failed attempt to progress. Errors polling cannot fix are returned right away. A zero wait calls
+// fetch exactly once.
+func waitFor(
+	ctx context.Context,
+	wait, interval time.Duration,
+	progress io.Writer,
+	fetch func(ctx context.Context) error,
+) error {
+	if wait <= 0 {
+		return fetch(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := fetch(ctx)
+		if err == nil {
+			if attempt > 1 {
+				fmt.Fprintf(progress, "endpoint ready after %s\n", time.Since(start).Round(time.Second))
+			}
+			return nil
+		}
+		if permanent(err) {
+			return err
+		}
+		fmt.Fprintf(progress, "waiting for endpoint (attempt %d, %s elapsed): %v\n", attempt, time.Since(start).Round(time.Second), err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("endpoint not ready after %s: %w", wait, err)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// permanent reports whether err persists however long the endpoint is given, i.e. the endpoint
+// is up but rejects the request or does not answer with a usable schema.
+func permanent(err error) bool {
+	var httpErr *gqlfetch.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 &&
+			httpErr.StatusCode != http.StatusRequestTimeout && httpErr.StatusCode != http.StatusTooManyRequests
+	}
+	var contentTypeErr *gqlfetch.ContentTypeError
+	var invalidSchemaErr *gqlfetch.InvalidSchemaError
+	var typeRefErr *gqlfetch.MalformedTypeRefError
+	return errors.As(err, &contentTypeErr) || errors.As(err, &invalidSchemaErr) || errors.As(err, &typeRefErr)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/suessflorian/gqlfetch"
+)
+
+func Test_waitFor(t *testing.T) {
+	refused := errors.New("connection refused")
+
+	t.Run("succeeds after failed attempts", func(t *testing.T) {
+		var attempts int
+		progress := &strings.Builder{}
+		err := waitFor(context.Background(), time.Minute, time.Millisecond, progress, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return refused
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("waitFor() error = %v", err)
+		}
+		if attempts != 3 {
+			t.Errorf("waitFor() made %d attempts, want 3", attempts)
+		}
+		if got := strings.Count(progress.String(), "waiting for endpoint"); got != 2 {
+			t.Errorf("waitFor() reported %d failed attempts, want 2:\n%s", got, progress)
+		}
+		if !strings.Contains(progress.String(), "endpoint ready after") {
+			t.Errorf("waitFor() did not report the endpoint ready:\n%s", progress)
+		}
+	})
+
+	t.Run("gives up at the deadline", func(t *testing.T) {
+		var attempts int
+		err := waitFor(context.Background(), 20*time.Millisecond, time.Millisecond, &strings.Builder{}, func(ctx context.Context) error {
+			attempts++
+			return refused
+		})
+		if !errors.Is(err, refused) || !strings.HasPrefix(err.Error(), "endpoint not ready after 20ms") {
+			t.Errorf("waitFor() error = %v, want endpoint not ready wrapping the last error", err)
+		}
+		if attempts < 2 {
+			t.Errorf("waitFor() made %d attempts, want it to keep polling until the deadline", attempts)
+		}
+	})
+
+	t.Run("without wait", func(t *testing.T) {
+		var attempts int
+		err := waitFor(context.Background(), 0, time.Millisecond, &strings.Builder{}, func(ctx context.Context) error {
+			attempts++
+			return refused
+		})
+		if err != refused || attempts != 1 {
+			t.Errorf("waitFor() = %v after %d attempts, want the first error", err, attempts)
+		}
+	})
+
+	for name, permanentErr := range map[string]error{
+		"unauthorized":       &gqlfetch.HTTPError{StatusCode: http.StatusUnauthorized},
+		"bad request":        &gqlfetch.HTTPError{StatusCode: http.StatusBadRequest},
+		"unexpected content": &gqlfetch.ContentTypeError{ContentType: "text/html"},
+		"invalid schema":     &gqlfetch.InvalidSchemaError{Message: "Undefined type Foo."},
+		"malformed type ref": fmt.Errorf("unable to write types: %w", &gqlfetch.MalformedTypeRefError{Path: "Query.foo"}),
+	} {
+		t.Run("stops on "+name, func(t *testing.T) {
+			var attempts int
+			err := waitFor(context.Background(), time.Minute, time.Millisecond, &strings.Builder{}, func(ctx context.Context) error {
+				attempts++
+				return permanentErr
+			})
+			if err != permanentErr {
+				t.Errorf("waitFor() error = %v, want %v", err, permanentErr)
+			}
+			if attempts != 1 {
+				t.Errorf("waitFor() made %d attempts, want 1", attempts)
+			}
+		})
+	}
+
+	t.Run("keeps polling unavailable endpoints", func(t *testing.T) {
+		var attempts int
+		err := waitFor(context.Background(), time.Minute, time.Millisecond, &strings.Builder{}, func(ctx context.Context) error {
+			attempts++
+			if attempts < 2 {
+				return &gqlfetch.HTTPError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return nil
+		})
+		if err != nil || attempts != 2 {
+			t.Errorf("waitFor() = %v after %d attempts, want success after 2", err, attempts)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.graphql")
+	if err := os.WriteFile(path, []byte("previous"), 0o644); err != nil {
+		t.Fatalf("unable to write fixture: %v", err)
+	}
+
+	err := Write(path, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return errors.New("introspection failed")
+	})
+	if err == nil {
+		t.Fatalf("Write() expected error")
+	}
+	if got, _ := os.ReadFile(path); string(got) != "previous" {
+		t.Errorf("failed Write() replaced file with %q", got)
+	}
+
+	err = Write(path, func(w io.Writer) error {
+		_, err := w.Write([]byte("type Query"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "type Query" {
+		t.Errorf("Write() produced %q", got)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected temporary files to be cleaned up, found %d entries", len(entries))
+	}
+}
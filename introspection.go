@@ -1,37 +1,76 @@
 package gqlfetch
 
 import (
+	_ "embed"
 	"encoding/json"
-	"log"
+	"fmt"
+	"strings"
+	"text/template"
 
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
-type introspectionResults struct {
-	Errors []struct {
-		Message string `json:"message"`
-	} `json:"errors"`
-	Data struct {
-		Schema introspectionSchema `json:"__schema"`
-	} `json:"data"`
+//go:embed introspect.graphql
+var introspectTemplate string
+
+var introspectQuery = template.Must(template.New("introspect.graphql").Parse(introspectTemplate))
+
+// introspectionFeatures toggles the introspection fields beyond the June 2018 specification.
+type introspectionFeatures struct {
+	DirectiveIsRepeatable bool
+	SpecifiedByURL        bool
+	InputValueDeprecation bool
+	OneOf                 bool
+}
+
+func (v SpecVersion) features() (introspectionFeatures, error) {
+	switch v {
+	case "", SpecJune2018:
+		return introspectionFeatures{}, nil
+	case SpecOctober2021:
+		return introspectionFeatures{
+			DirectiveIsRepeatable: true,
+			SpecifiedByURL:        true,
+		}, nil
+	case SpecSeptember2025:
+		return introspectionFeatures{
+			DirectiveIsRepeatable: true,
+			SpecifiedByURL:        true,
+			InputValueDeprecation: true,
+			OneOf:                 true,
+		}, nil
+	default:
+		return introspectionFeatures{}, fmt.Errorf("unsupported spec version: %s", v)
+	}
+}
+
+func buildIntrospectionQuery(features introspectionFeatures) (string, error) {
+	sb := &strings.Builder{}
+	if err := introspectQuery.Execute(sb, features); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
 }
 
 type introspectionSchema struct {
-	QueryType    ast.Definition                     `json:"queryType"`
-	MutationType ast.Definition                     `json:"mutationType"`
-	Types        []introspectionTypeDefinition      `json:"types"`
-	Directives   []introspectionDirectiveDefinition `json:"directives"`
+	QueryType        ast.Definition                     `json:"queryType"`
+	MutationType     ast.Definition                     `json:"mutationType"`
+	SubscriptionType ast.Definition                     `json:"subscriptionType"`
+	Types            []introspectionTypeDefinition      `json:"types"`
+	Directives       []introspectionDirectiveDefinition `json:"directives"`
 }
 
 type introspectionTypeDefinition struct {
-	Kind          ast.DefinitionKind        `json:"kind"`
-	Name          string                    `json:"name"`
-	Description   string                    `json:"description"`
-	Fields        []introspectedTypeField   `json:"fields"`
-	InputFields   []introspectionInputField `json:"inputFields"`
-	Interfaces    []ast.Definition          `json:"interfaces"`
-	EnumValues    json.RawMessage           `json:"enumValues"`
-	PossibleTypes json.RawMessage           `json:"possibleTypes"`
+	Kind           ast.DefinitionKind        `json:"kind"`
+	Name           string                    `json:"name"`
+	Description    string                    `json:"description"`
+	SpecifiedByURL *string                   `json:"specifiedByURL"`
+	IsOneOf        bool                      `json:"isOneOf"`
+	Fields         []introspectedTypeField   `json:"fields"`
+	InputFields    []introspectionInputField `json:"inputFields"`
+	Interfaces     []ast.Definition          `json:"interfaces"`
+	EnumValues     []introspectedEnumValue   `json:"enumValues"`
+	PossibleTypes  []*introspectedType       `json:"possibleTypes"`
 }
 
 type introspectedTypeField struct {
@@ -51,22 +90,20 @@ type introspectedEnumValue struct {
 }
 
 type introspectionDirectiveDefinition struct {
-	Name        string                  `json:"name"`
-	Description string                  `json:"description"`
-	Locations   []ast.DirectiveLocation `json:"locations"`
-	Args        []struct {
-		Name         string            `json:"name"`
-		Description  string            `json:"description"`
-		Type         *introspectedType `json:"type"`
-		DefaultValue interface{}       `json:"defaultValue"`
-	} `json:"args"`
+	Name         string                    `json:"name"`
+	Description  string                    `json:"description"`
+	Locations    []ast.DirectiveLocation   `json:"locations"`
+	IsRepeatable bool                      `json:"isRepeatable"`
+	Args         []introspectionInputField `json:"args"`
 }
 
 type introspectionInputField struct {
-	Name         string            `json:"name"`
-	Description  string            `json:"description"`
-	Type         *introspectedType `json:"type"`
-	DefaultValue interface{}       `json:"defaultValue"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	Type              *introspectedType `json:"type"`
+	DefaultValue      interface{}       `json:"defaultValue"`
+	IsDeprecated      bool              `json:"isDeprecated"`
+	DeprecationReason interface{}       `json:"deprecationReason"`
 }
 
 type introspectedType struct {
@@ -75,6 +112,62 @@ type introspectedType struct {
 	OfType *introspectedType     `json:"ofType"`
 }
 
+// MarshalJSON reports absent root operation types as null, as introspection does. The roots lead
+// the output since streaming consumers need them before the first type.
+func (s introspectionSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		QueryType        *introspectionTypeName             `json:"queryType"`
+		MutationType     *introspectionTypeName             `json:"mutationType"`
+		SubscriptionType *introspectionTypeName             `json:"subscriptionType"`
+		Types            []introspectionTypeDefinition      `json:"types"`
+		Directives       []introspectionDirectiveDefinition `json:"directives"`
+	}{
+		QueryType:        newIntrospectionTypeName(s.QueryType.Name),
+		MutationType:     newIntrospectionTypeName(s.MutationType.Name),
+		SubscriptionType: newIntrospectionTypeName(s.SubscriptionType.Name),
+		Types:            s.Types,
+		Directives:       s.Directives,
+	})
+}
+
+type introspectionTypeName struct {
+	Name string `json:"name"`
+}
+
+func newIntrospectionTypeName(name string) *introspectionTypeName {
+	if name == "" {
+		return nil
+	}
+	return &introspectionTypeName{Name: name}
+}
+
+// MarshalJSON reports interfaces as type references, the decoded definitions only retain their name.
+// isOneOf is null for anything but input objects, as the specification requires.
+func (t introspectionTypeDefinition) MarshalJSON() ([]byte, error) {
+	type typeDefinition introspectionTypeDefinition
+	var isOneOf *bool
+	if t.Kind == ast.InputObject {
+		isOneOf = &t.IsOneOf
+	}
+	var interfaces []*introspectedType
+	if t.Interfaces != nil {
+		interfaces = make([]*introspectedType, 0, len(t.Interfaces))
+	}
+	for _, intface := range t.Interfaces {
+		name := intface.Name
+		interfaces = append(interfaces, &introspectedType{Kind: introspectionTypeKind(ast.Interface), Name: &name})
+	}
+	return json.Marshal(struct {
+		typeDefinition
+		IsOneOf    *bool               `json:"isOneOf"`
+		Interfaces []*introspectedType `json:"interfaces"`
+	}{
+		typeDefinition: typeDefinition(t),
+		IsOneOf:        isOneOf,
+		Interfaces:     interfaces,
+	})
+}
+
 type introspectionTypeKind string
 
 const (
@@ -83,24 +176,38 @@ const (
 	OBJECT   introspectionTypeKind = "OBJECT"
 )
 
-func introspectionTypeToAstType(typ *introspectedType) *ast.Type {
+func introspectionTypeToAstType(typ *introspectedType, path string) (*ast.Type, error) {
+	if typ == nil {
+		return nil, &MalformedTypeRefError{Path: path, Reason: "missing type reference"}
+	}
+
 	var res ast.Type
 	if typ.OfType == nil {
+		if typ.Name == nil {
+			return nil, &MalformedTypeRefError{Path: path, Kind: string(typ.Kind), Reason: "named type without a name"}
+		}
 		res.NamedType = *typ.Name
-		return &res
+		return &res, nil
 	}
 
 	switch typ.Kind {
 	case NON_NULL:
-		res = *introspectionTypeToAstType(typ.OfType)
+		elem, err := introspectionTypeToAstType(typ.OfType, path)
+		if err != nil {
+			return nil, err
+		}
+		res = *elem
 		res.NonNull = true
-		return &res
+		return &res, nil
 	case LIST:
-		res.Elem = introspectionTypeToAstType(typ.OfType)
-		return &res
+		elem, err := introspectionTypeToAstType(typ.OfType, path)
+		if err != nil {
+			return nil, err
+		}
+		res.Elem = elem
+		return &res, nil
 	default:
-		log.Fatalf("type kind unknown: %s", typ.Kind)
-		return nil
+		return nil, &MalformedTypeRefError{Path: path, Kind: string(typ.Kind), Reason: "type kind unknown"}
 	}
 }
 
@@ -117,3 +224,12 @@ func containsStr(needle string, hay []string) bool {
 	}
 	return false
 }
+
+func containsType(name string, types []introspectionTypeDefinition) bool {
+	for _, typ := range types {
+		if typ.Name == name {
+			return true
+		}
+	}
+	return false
+}
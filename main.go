@@ -1,29 +1,67 @@
 package gqlfetch
 
 import (
+	"bufio"
 	"bytes"
 	"context"
-	_ "embed"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
-//go:embed introspect.graphql
-var introspectSchema string
+// SpecVersion selects which edition of the GraphQL specification the introspection query targets.
+type SpecVersion string
+
+const (
+	// SpecJune2018 is understood by practically every server and is the default.
+	SpecJune2018 SpecVersion = "June2018"
+	// SpecOctober2021 adds repeatable directives and scalar specification URLs.
+	SpecOctober2021 SpecVersion = "October2021"
+	// SpecSeptember2025 adds deprecated arguments and input fields as well as oneOf input objects.
+	SpecSeptember2025 SpecVersion = "September2025"
+)
+
+// Doer executes the introspection requests, *http.Client satisfies it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
 
 type BuildClientSchemaOptions struct {
-	Endpoint        string
+	Endpoint string
+	// Method defaults to POST, GET requests carry the query as URL query parameters.
 	Method          string
 	Headers         http.Header
 	WithoutBuiltins bool
+	// HTTPClient defaults to an http.Client with a two minute timeout.
+	HTTPClient Doer
+	// SpecVersion defaults to SpecJune2018 when empty.
+	SpecVersion SpecVersion
+	// Retry is disabled by default.
+	Retry RetryPolicy
+	// Debug receives request and download size/time diagnostics when set.
+	Debug io.Writer
+	// Probe discovers which introspection fields the server supports before introspecting,
+	// requesting the richest schema the server accepts. SpecVersion is ignored when set.
+	Probe bool
+	// Sort disables streaming when types are sorted or grouped, all types are held until the
+	// introspection result has been read.
+	Sort SortOptions
+	// Print controls the layout of the printed schema.
+	Print PrintOptions
+	// Renderer defaults to RendererPrinter.
+	Renderer Renderer
+	// Validate loads the printed schema with gqlparser before handing it out, returning an
+	// *InvalidSchemaError if it does not load. The schema is no longer streamed when set.
+	Validate bool
 }
 
 func BuildClientSchema(ctx context.Context, endpoint string, withoutBuiltins bool) (string, error) {
@@ -45,57 +83,311 @@ func BuildClientSchemaWithHeaders(ctx context.Context, endpoint string, headers
 }
 
 func BuildClientSchemaWithOptions(ctx context.Context, options BuildClientSchemaOptions) (string, error) {
-	buffer := new(bytes.Buffer)
-	if err := json.NewEncoder(buffer).Encode(struct {
-		Query string `json:"query"`
-	}{Query: introspectSchema}); err != nil {
-		return "", fmt.Errorf("failed to prepare introspection query request: %w", err)
+	sb := &strings.Builder{}
+	if err := WriteClientSchema(ctx, sb, options); err != nil {
+		return "", err
 	}
+	return sb.String(), nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, options.Method, options.Endpoint, buffer)
+// WriteClientSchema writes the schema to w type by type as the introspection result is decoded,
+// directives reported after the types are written once the types are done. On error w may have
+// received part of the schema, write to a temporary file when that matters.
+func WriteClientSchema(ctx context.Context, w io.Writer, options BuildClientSchemaOptions) error {
+	body, err := fetchIntrospection(ctx, options)
 	if err != nil {
-		return "", fmt.Errorf("failed to create query request: %w", err)
+		return err
 	}
+	defer body.Close()
+
+	return decodeAndWriteSchema(w, body, options)
+}
 
-	// If no headers are provided, create an empty header map, so we can add the content type header
-	if options.Headers == nil {
-		options.Headers = make(http.Header)
+// BuildClientSchemaAST introspects the endpoint and converts the result straight into a validated
+// gqlparser schema, saving consumers a print/parse round trip.
+func BuildClientSchemaAST(ctx context.Context, options BuildClientSchemaOptions) (*ast.Schema, error) {
+	body, err := fetchIntrospection(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	schema, err := decodeSchema(body)
+	if err != nil {
+		return nil, err
 	}
-	req.Header = http.Header(options.Headers)
-	req.Header.Add("Content-Type", "application/json")
 
-	client := http.Client{Timeout: 2 * time.Minute}
-	res, err := client.Do(req)
+	return introspectionSchemaToAst(schema)
+}
+
+// fetchIntrospection sends the introspection query, the caller is responsible for closing the body.
+func fetchIntrospection(ctx context.Context, options BuildClientSchemaOptions) (io.ReadCloser, error) {
+	var features introspectionFeatures
+	var err error
+	if options.Probe {
+		features, err = probeFeatures(ctx, options)
+	} else {
+		features, err = options.SpecVersion.features()
+	}
 	if err != nil {
-		return "", fmt.Errorf("unable to download schema: %w", err)
+		return nil, err
 	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unable to download schema: %s", res.Status)
+	query, err := buildIntrospectionQuery(features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare introspection query: %w", err)
 	}
 
-	return decodeAndPrintSchema(res.Body, options)
+	return executeQuery(ctx, options, "IntrospectionQuery", query)
+}
+
+// executeQuery sends the query to the endpoint, retrying according to the retry policy.
+// The caller is responsible for closing the body.
+func executeQuery(ctx context.Context, options BuildClientSchemaOptions, operationName, query string) (io.ReadCloser, error) {
+	client := options.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Minute}
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := newQueryRequest(ctx, options, operationName, query)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		res, err := client.Do(req)
+		var wire *countingReader
+		if err == nil {
+			if options.Debug != nil {
+				fmt.Fprintf(options.Debug, "%s %s: %s\n", req.Method, options.Endpoint, res.Status)
+			}
+			// Error responses are decoded by newHTTPError, which falls back to the raw body, so a
+			// garbled error body does not bypass the retry policy.
+			if res.StatusCode == http.StatusOK {
+				wire = &countingReader{reader: res.Body}
+				decoded, err := decodeContentEncoding(readCloser{Reader: wire, Closer: res.Body}, res.Header.Get("Content-Encoding"))
+				if err != nil {
+					res.Body.Close()
+					return nil, err
+				}
+				res.Body = decoded
+			}
+		}
+
+		final := attempt >= options.Retry.MaxAttempts || ctx.Err() != nil
+		if err != nil {
+			if final {
+				return nil, fmt.Errorf("unable to download schema: %w", err)
+			}
+		} else if res.StatusCode == http.StatusOK {
+			body, err := checkContentType(res)
+			if err != nil {
+				defer res.Body.Close()
+				return nil, err
+			}
+			if options.Debug != nil {
+				body = newDownloadStats(body, wire, res.Header.Get("Content-Encoding"), start, options.Debug)
+			}
+			return body, nil
+		} else if final || !options.Retry.retryableStatus(res.StatusCode) {
+			defer res.Body.Close()
+			return nil, newHTTPError(res)
+		} else {
+			res.Body.Close()
+		}
+
+		if err := sleep(ctx, options.Retry.backoff(attempt, res)); err != nil {
+			return nil, fmt.Errorf("unable to download schema: %w", err)
+		}
+	}
+}
+
+// newQueryRequest encodes the query as a JSON body, or as URL query parameters for GET requests
+// as described by the GraphQL-over-HTTP specification.
+func newQueryRequest(ctx context.Context, options BuildClientSchemaOptions, operationName, query string) (*http.Request, error) {
+	method := options.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	endpoint := options.Endpoint
+	var body io.Reader
+	if method == http.MethodGet {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse endpoint: %w", err)
+		}
+		params := u.Query()
+		params.Set("query", query)
+		params.Set("operationName", operationName)
+		u.RawQuery = params.Encode()
+		endpoint = u.String()
+	} else {
+		buffer := new(bytes.Buffer)
+		if err := json.NewEncoder(buffer).Encode(struct {
+			Query         string `json:"query"`
+			OperationName string `json:"operationName"`
+		}{Query: query, OperationName: operationName}); err != nil {
+			return nil, fmt.Errorf("failed to prepare introspection query request: %w", err)
+		}
+		body = buffer
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query request: %w", err)
+	}
+
+	// Clone the provided headers, so we can add the content type header without touching the caller's map
+	req.Header = options.Headers.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/graphql-response+json, application/json;q=0.9")
+	}
+	return req, nil
+}
+
+// checkContentType rejects responses that are not JSON. Besides the GraphQL-over-HTTP media types,
+// bodies that merely look like a JSON object are accepted since plenty of servers mislabel them.
+func checkContentType(res *http.Response) (io.ReadCloser, error) {
+	contentType := res.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/graphql-response+json" || mediaType == "application/json") {
+		return res.Body, nil
+	}
+
+	body := bufio.NewReader(res.Body)
+	for {
+		b, err := body.Peek(1)
+		if err == nil && unicode.IsSpace(rune(b[0])) {
+			body.ReadByte()
+			continue
+		}
+		if err == nil && b[0] == '{' {
+			return readCloser{Reader: body, Closer: res.Body}, nil
+		}
+		break
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(body, maxHTTPErrorBody))
+	return nil, &ContentTypeError{ContentType: contentType, Body: string(snippet)}
+}
+
+const (
+	// maxHTTPErrorBody bounds the snippet of an error response kept on HTTPError.
+	maxHTTPErrorBody = 4 << 10
+	// maxHTTPErrorDecode bounds how much of an error response is read looking for GraphQL errors.
+	maxHTTPErrorDecode = 1 << 20
+)
+
+func newHTTPError(res *http.Response) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Header:     res.Header,
+	}
+
+	body := readHTTPErrorBody(res)
+	var response struct {
+		Errors GraphQLErrors `json:"errors"`
+	}
+	if json.Unmarshal(body, &response) == nil {
+		httpErr.Errors = response.Errors
+	}
+
+	if len(body) > maxHTTPErrorBody {
+		body = body[:maxHTTPErrorBody]
+	}
+	httpErr.Body = string(body)
+	return httpErr
+}
+
+// readHTTPErrorBody reads the error response, decoded according to its Content-Encoding when
+// possible. Error bodies are frequently empty or not encoded as advertised, the raw bytes are
+// returned then.
+func readHTTPErrorBody(res *http.Response) []byte {
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, maxHTTPErrorDecode))
+	encoding := res.Header.Get("Content-Encoding")
+	if encoding == "" {
+		return raw
+	}
+	decoded, err := decodeContentEncoding(io.NopCloser(bytes.NewReader(raw)), encoding)
+	if err != nil {
+		return raw
+	}
+	body, err := io.ReadAll(io.LimitReader(decoded, maxHTTPErrorDecode))
+	if err != nil {
+		return raw
+	}
+	return body
 }
 
 func decodeAndPrintSchema(
 	schema io.Reader,
 	options BuildClientSchemaOptions,
 ) (string, error) {
-	var schemaResponse introspectionResults
-
-	if err := json.NewDecoder(schema).Decode(&schemaResponse); err != nil {
-		return "", fmt.Errorf("unable to decode schema: %w", err)
+	sb := &strings.Builder{}
+	if err := decodeAndWriteSchema(sb, schema, options); err != nil {
+		return "", err
 	}
+	return sb.String(), nil
+}
 
-	if len(schemaResponse.Errors) != 0 {
-		var errs []string
-		for _, err := range schemaResponse.Errors {
-			errs = append(errs, err.Message)
+// decodeAndWriteSchema prints types as they are decoded, the introspection result is never held in
+// full unless types are sorted, the printed schema is validated or the formatter renders it.
+func decodeAndWriteSchema(w io.Writer, schema io.Reader, options BuildClientSchemaOptions) error {
+	if err := options.Print.validate(); err != nil {
+		return err
+	}
+	if options.Validate {
+		options.Validate = false
+		sb := &strings.Builder{}
+		if err := decodeAndWriteSchema(sb, schema, options); err != nil {
+			return err
+		}
+		if _, err := loadSDL(sb.String()); err != nil {
+			return err
 		}
-		return "", errors.New("encountered the following GraphQL errors: " + strings.Join(errs, ","))
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return fmt.Errorf("unable to write schema: %w", err)
+		}
+		return nil
+	}
+
+	switch options.Renderer {
+	case "", RendererPrinter:
+	case RendererFormatter:
+		return renderWithFormatter(w, schema, options)
+	default:
+		return fmt.Errorf("unsupported renderer: %s", options.Renderer)
 	}
 
-	return printSchema(schemaResponse.Data.Schema, options.WithoutBuiltins), nil
+	printer := newSchemaPrinter(w, options.WithoutBuiltins, options.Print)
+	sorter := newSortingHandler(printer, options.Sort)
+	if err := streamSchema(schema, sorter); err != nil {
+		return err
+	}
+	if err := sorter.flush(); err != nil {
+		return err
+	}
+	return printer.finish()
+}
+
+func decodeSchema(schema io.Reader) (introspectionSchema, error) {
+	collector := &schemaCollector{}
+	if err := streamSchema(schema, collector); err != nil {
+		return introspectionSchema{}, err
+	}
+
+	return collector.schema, nil
 }
 
 func BuildClientSchemaFromFile(
@@ -109,46 +401,142 @@ func BuildClientSchemaFromFile(
 	}
 
 	defer f.Close()
-	return decodeAndPrintSchema(f, BuildClientSchemaOptions{
+	return BuildClientSchemaFromReader(ctx, f, BuildClientSchemaOptions{
 		WithoutBuiltins: withoutBuiltins,
 	})
 }
 
-func printSchema(schema introspectionSchema, withoutBuiltins bool) string {
-	sb := &strings.Builder{}
+// BuildClientSchemaFromReader converts an introspection result read from r, e.g. os.Stdin.
+// Only the options shaping the printed schema apply, those describing the request are ignored.
+// Reading stops with ctx's error once ctx is done.
+func BuildClientSchemaFromReader(
+	ctx context.Context,
+	r io.Reader,
+	options BuildClientSchemaOptions,
+) (string, error) {
+	return decodeAndPrintSchema(contextReader{ctx: ctx, r: r}, options)
+}
+
+// WriteClientSchemaFromReader streams the schema of an introspection result read from r to w,
+// see BuildClientSchemaFromReader.
+func WriteClientSchemaFromReader(
+	ctx context.Context,
+	w io.Writer,
+	r io.Reader,
+	options BuildClientSchemaOptions,
+) error {
+	return decodeAndWriteSchema(w, contextReader{ctx: ctx, r: r}, options)
+}
 
-	err := printDirectives(sb, schema.Directives, withoutBuiltins)
+// WriteClientSchemaFromFile streams the schema of an introspection result stored on disk to w.
+func WriteClientSchemaFromFile(
+	ctx context.Context,
+	w io.Writer,
+	filePath string,
+	withoutBuiltins bool,
+) error {
+	f, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Sprintf("unable to write directives: %v", err)
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+
+	defer f.Close()
+	return WriteClientSchemaFromReader(ctx, w, f, BuildClientSchemaOptions{
+		WithoutBuiltins: withoutBuiltins,
+	})
+}
+
+// contextReader fails reads once ctx is done, so decoding a slow reader such as stdin can be
+// cancelled between reads.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
 	}
-	err = printTypes(sb, schema.Types, withoutBuiltins)
+	return r.r.Read(p)
+}
+
+// BuildClientSchemaASTFromFile converts an introspection result stored on disk into a validated
+// gqlparser schema.
+func BuildClientSchemaASTFromFile(ctx context.Context, filePath string) (*ast.Schema, error) {
+	f, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Sprintf("unable to write types: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	return sb.String()
+	defer f.Close()
+	schema, err := decodeSchema(contextReader{ctx: ctx, r: f})
+	if err != nil {
+		return nil, err
+	}
+
+	return introspectionSchemaToAst(schema)
+}
+
+// printSchemaDefinition writes an explicit schema block whenever needsSchemaDefinition says so.
+func printSchemaDefinition(sb *strings.Builder, schema introspectionSchema, options PrintOptions) {
+	if !needsSchemaDefinition(schema) {
+		return
+	}
+
+	sb.WriteString("schema {\n")
+	for _, root := range schemaRoots(schema) {
+		if root.name != "" {
+			sb.WriteString(fmt.Sprintf("%s%s: %s\n", options.indent(1), root.operation, root.name))
+		}
+	}
+	sb.WriteString("}\n")
+	sb.WriteString("\n")
+}
+
+type schemaRoot struct {
+	operation string
+	name      string
+	implicit  string
 }
 
-func printDirectives(sb *strings.Builder, directives []introspectionDirectiveDefinition, withoutBuiltins bool) error {
+func schemaRoots(schema introspectionSchema) []schemaRoot {
+	return []schemaRoot{
+		{operation: "query", name: schema.QueryType.Name, implicit: "Query"},
+		{operation: "mutation", name: schema.MutationType.Name, implicit: "Mutation"},
+		{operation: "subscription", name: schema.SubscriptionType.Name, implicit: "Subscription"},
+	}
+}
+
+// needsSchemaDefinition reports whether the conventional root type names would not resolve to
+// the roots the server reported.
+func needsSchemaDefinition(schema introspectionSchema) bool {
+	for _, root := range schemaRoots(schema) {
+		if root.name != "" && root.name != root.implicit {
+			return true
+		}
+		if root.name == "" && containsType(root.implicit, schema.Types) {
+			return true
+		}
+	}
+	return false
+}
+
+func printDirectives(sb *strings.Builder, directives []introspectionDirectiveDefinition, withoutBuiltins bool, options PrintOptions) error {
 	for _, directive := range directives {
 		if withoutBuiltins && containsStr(directive.Name, excludeDirectives) {
 			continue
 		}
-		err := printDescription(sb, directive.Description)
+		err := printDescription(sb, "", directive.Description, options.Descriptions)
 		if err != nil {
 			return fmt.Errorf("unable to write directive description for %s: %w", directive.Name, err)
 		}
 		sb.WriteString(fmt.Sprintf("directive @%s", directive.Name))
-		if len(directive.Args) > 0 {
-			sb.WriteString("(\n")
-			for _, arg := range directive.Args {
-				err = printDescription(sb, arg.Description)
-				if err != nil {
-					return fmt.Errorf("unable to write description for arg %s.%s: %w", directive.Name, arg.Name, err)
-				}
-				sb.WriteString(fmt.Sprintf("\t%s: %s\n", arg.Name, introspectionTypeToAstType(arg.Type).String()))
-			}
-			sb.WriteString(")")
+		err = printArguments(sb, directive.Args, "@"+directive.Name, 0, options)
+		if err != nil {
+			return err
+		}
+		if directive.IsRepeatable {
+			sb.WriteString(" repeatable")
 		}
 
 		sb.WriteString(" on ")
@@ -164,7 +552,8 @@ func printDirectives(sb *strings.Builder, directives []introspectionDirectiveDef
 	return nil
 }
 
-func printTypes(sb *strings.Builder, types []introspectionTypeDefinition, withoutBuiltins bool) error {
+func printTypes(sb *strings.Builder, types []introspectionTypeDefinition, withoutBuiltins bool, options PrintOptions) error {
+	indent := options.indent(1)
 	for _, typ := range types {
 		if strings.HasPrefix(typ.Name, "__") {
 			continue
@@ -172,7 +561,7 @@ func printTypes(sb *strings.Builder, types []introspectionTypeDefinition, withou
 		if withoutBuiltins && containsStr(typ.Name, excludeScalarTypes) && typ.Kind == ast.Scalar {
 			continue
 		}
-		err := printDescription(sb, typ.Description)
+		err := printDescription(sb, "", typ.Description, options.Descriptions)
 		if err != nil {
 			return fmt.Errorf("unable to write description for type %s: %w", typ.Name, err)
 		}
@@ -189,88 +578,70 @@ func printTypes(sb *strings.Builder, types []introspectionTypeDefinition, withou
 						sb.WriteString(" & ")
 					}
 				}
+				sb.WriteString(" ")
 			}
 			sb.WriteString("{\n")
-			for _, field := range typ.Fields {
-				err = printDescription(sb, field.Description)
-				if err != nil {
-					return fmt.Errorf("unable to write description for field %s.%s: %w", typ.Name, field.Name, err)
-				}
-				sb.WriteString(fmt.Sprintf("\t%s", field.Name))
-				if len(field.Args) > 0 {
-					sb.WriteString("(\n")
-					for _, arg := range field.Args {
-						err = printDescription(sb, arg.Description)
-						if err != nil {
-							return fmt.Errorf("unable to write description for arg %s.%s.%s: %w", typ.Name, field.Name, arg.Name, err)
-						}
-						sb.WriteString(fmt.Sprintf("\t\t%s: %s\n", arg.Name, introspectionTypeToAstType(arg.Type).String()))
-					}
-					sb.WriteString("\t)")
-				}
-				sb.WriteString(fmt.Sprintf(": %s", introspectionTypeToAstType(field.Type).String()))
-				if field.IsDeprecated {
-					sb.WriteString(" @deprecated")
-					if reason, ok := field.DeprecationReason.(string); ok && reason != "" {
-						sb.WriteString(fmt.Sprintf("(reason: \"%s\")", reason))
-					}
-				}
-				sb.WriteString("\n")
+			err = printFields(sb, typ, options)
+			if err != nil {
+				return err
 			}
 			sb.WriteString("}")
 
 		case ast.Union:
-			sb.WriteString(fmt.Sprintf("union %s =", typ.Name))
-			var possible []*introspectedType
-			if err := json.Unmarshal(typ.PossibleTypes, &possible); err != nil {
-				return fmt.Errorf("unable to unmarshal possible types for %s: %w", typ.Name, err)
-			}
-			for i, typ := range possible {
-				sb.WriteString(introspectionTypeToAstType(typ).String())
-				if i < len(possible)-1 {
+			sb.WriteString(fmt.Sprintf("union %s = ", typ.Name))
+			for i, possibleType := range typ.PossibleTypes {
+				member, err := introspectionTypeToAstType(possibleType, typ.Name)
+				if err != nil {
+					return err
+				}
+				sb.WriteString(member.String())
+				if i < len(typ.PossibleTypes)-1 {
 					sb.WriteString(" | ")
 				}
 			}
 
 		case ast.Enum:
 			sb.WriteString(fmt.Sprintf("enum %s {\n", typ.Name))
-			var enumValues []introspectedEnumValue
-			if err := json.Unmarshal(typ.EnumValues, &enumValues); err != nil {
-				return fmt.Errorf("unable to unmarshal enum values for %s: %w", typ.Name, err)
-			}
-			for _, value := range enumValues {
-				err = printDescription(sb, value.Description)
+			for _, value := range typ.EnumValues {
+				err = printDescription(sb, indent, value.Description, options.Descriptions)
 				if err != nil {
 					return fmt.Errorf("unable to write description for enum value %s.%s: %w", typ.Name, value.Name, err)
 				}
 
-				sb.WriteString(fmt.Sprintf("\t%s", value.Name))
-				if value.IsDeprecated {
-					sb.WriteString(" @deprecated")
-					if reason, ok := value.DeprecationReason.(string); ok && reason != "" {
-						sb.WriteString(fmt.Sprintf("(reason: \"%s\")", reason))
-					}
-				}
+				sb.WriteString(fmt.Sprintf("%s%s", indent, value.Name))
+				printDeprecated(sb, value.IsDeprecated, value.DeprecationReason)
 				sb.WriteString("\n")
 			}
 			sb.WriteString("}")
 
 		case ast.Scalar:
 			sb.WriteString(fmt.Sprintf("scalar %s", typ.Name))
+			if typ.SpecifiedByURL != nil {
+				sb.WriteString(fmt.Sprintf(" @specifiedBy(url: %s)", quoteString(*typ.SpecifiedByURL)))
+			}
 
 		case ast.InputObject:
-			sb.WriteString(fmt.Sprintf("input %s {\n", typ.Name))
+			sb.WriteString(fmt.Sprintf("input %s ", typ.Name))
+			if typ.IsOneOf {
+				sb.WriteString("@oneOf ")
+			}
+			sb.WriteString("{\n")
 			for _, field := range typ.InputFields {
-				err = printDescription(sb, typ.Description)
+				err = printDescription(sb, indent, field.Description, options.Descriptions)
 				if err != nil {
 					return fmt.Errorf("unable to write description for input field %s.%s: %w", typ.Name, field.Name, err)
 				}
-				sb.WriteString(fmt.Sprintf("\t%s: %s\n", field.Name, introspectionTypeToAstType(field.Type).String()))
+				sb.WriteString(indent)
+				err = printInputValue(sb, field, typ.Name)
+				if err != nil {
+					return err
+				}
+				sb.WriteString("\n")
 			}
 			sb.WriteString("}")
 
 		case ast.Interface:
-			err = printInterface(sb, typ)
+			err = printInterface(sb, typ, options)
 			if err != nil {
 				return fmt.Errorf("unable to write interface %s: %w", typ.Name, err)
 			}
@@ -283,38 +654,221 @@ func printTypes(sb *strings.Builder, types []introspectionTypeDefinition, withou
 	return nil
 }
 
-func printDescription(sb *strings.Builder, description string) error {
-	if description != "" {
-		sb.WriteString(`"""`)
+// printFields writes the fields of an object or interface, one per line.
+func printFields(sb *strings.Builder, typ introspectionTypeDefinition, options PrintOptions) error {
+	indent := options.indent(1)
+	for _, field := range typ.Fields {
+		err := printDescription(sb, indent, field.Description, options.Descriptions)
+		if err != nil {
+			return fmt.Errorf("unable to write description for field %s.%s: %w", typ.Name, field.Name, err)
+		}
+		sb.WriteString(fmt.Sprintf("%s%s", indent, field.Name))
+		err = printArguments(sb, field.Args, fmt.Sprintf("%s.%s", typ.Name, field.Name), 1, options)
+		if err != nil {
+			return err
+		}
+		fieldType, err := introspectionTypeToAstType(field.Type, fmt.Sprintf("%s.%s", typ.Name, field.Name))
+		if err != nil {
+			return err
+		}
+		sb.WriteString(fmt.Sprintf(": %s", fieldType.String()))
+		printDeprecated(sb, field.IsDeprecated, field.DeprecationReason)
+		sb.WriteString("\n")
+	}
+	return nil
+}
+
+// printArguments writes the parenthesised arguments of the field or directive at path, depth is the
+// indentation depth of the field or directive itself. Arguments go on a line each, unless
+// options.InlineArgs allows the list on a single line.
+func printArguments(sb *strings.Builder, args []introspectionInputField, path string, depth int, options PrintOptions) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	if options.InlineArgs > 0 {
+		inline := &strings.Builder{}
+		described := false
+		inline.WriteString("(")
+		for i, arg := range args {
+			described = described || arg.Description != ""
+			if i > 0 {
+				inline.WriteString(", ")
+			}
+			if err := printInputValue(inline, arg, path); err != nil {
+				return err
+			}
+		}
+		inline.WriteString(")")
+		if !described && inline.Len() < options.InlineArgs {
+			sb.WriteString(inline.String())
+			return nil
+		}
+	}
+
+	indent := options.indent(depth + 1)
+	sb.WriteString("(\n")
+	for _, arg := range args {
+		err := printDescription(sb, indent, arg.Description, options.Descriptions)
+		if err != nil {
+			return fmt.Errorf("unable to write description for arg %s.%s: %w", path, arg.Name, err)
+		}
+		sb.WriteString(indent)
+		if err := printInputValue(sb, arg, path); err != nil {
+			return err
+		}
 		sb.WriteString("\n")
-		sb.WriteString(description)
+	}
+	sb.WriteString(options.indent(depth))
+	sb.WriteString(")")
+	return nil
+}
+
+// printInputValue writes an argument or input field of the element at path without its description.
+func printInputValue(sb *strings.Builder, value introspectionInputField, path string) error {
+	valueType, err := introspectionTypeToAstType(value.Type, fmt.Sprintf("%s.%s", path, value.Name))
+	if err != nil {
+		return err
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s", value.Name, valueType.String()))
+	printDefaultValue(sb, value.DefaultValue)
+	printDeprecated(sb, value.IsDeprecated, value.DeprecationReason)
+	return nil
+}
+
+// printDescription writes the description as a block string on its own lines, single-line
+// descriptions as style asks. Descriptions a block string cannot reproduce exactly fall back to a
+// single-line string.
+func printDescription(sb *strings.Builder, indent, description string, style DescriptionStyle) error {
+	if description == "" {
+		return nil
+	}
+	sb.WriteString(indent)
+	singleLine := !strings.Contains(description, "\n")
+	if !isPrintableAsBlockString(description) || singleLine && style == DescriptionString {
+		sb.WriteString(quoteString(description))
 		sb.WriteString("\n")
+		return nil
+	}
+
+	escaped := strings.ReplaceAll(description, `"""`, `\"""`)
+	// A closing quote or backslash would run into the closing """ on the same line.
+	if singleLine && style == DescriptionCompact && !strings.HasSuffix(description, `"`) && !strings.HasSuffix(description, `\`) {
 		sb.WriteString(`"""`)
+		sb.WriteString(escaped)
+		sb.WriteString(`"""`)
+		sb.WriteString("\n")
+		return nil
+	}
+
+	sb.WriteString(`"""`)
+	sb.WriteString("\n")
+	for _, line := range strings.Split(escaped, "\n") {
+		if line != "" {
+			sb.WriteString(indent)
+			sb.WriteString(line)
+		}
 		sb.WriteString("\n")
 	}
+	sb.WriteString(indent)
+	sb.WriteString(`"""`)
+	sb.WriteString("\n")
 	return nil
 }
 
-func printInterface(sb *strings.Builder, typ introspectionTypeDefinition) error {
+// isPrintableAsBlockString reports whether a block string holding value on its own lines reads back
+// as value. Block string values lose carriage returns, leading and trailing blank lines and the
+// indentation common to all lines.
+func isPrintableAsBlockString(value string) bool {
+	lines := strings.Split(value, "\n")
+	if strings.TrimLeft(lines[0], " \t") == "" || strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		return false
+	}
+
+	commonIndent := true
+	for _, line := range lines {
+		for _, r := range line {
+			if r < ' ' && r != '\t' {
+				return false
+			}
+		}
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" && trimmed == line {
+			commonIndent = false
+		}
+	}
+	return !commonIndent
+}
+
+// quoteString returns value as a single-line GraphQL string literal, for descriptions and directive
+// arguments alike.
+func quoteString(value string) string {
+	sb := &strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if r < ' ' {
+				sb.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// printDefaultValue writes the default value of an argument or input field, introspection
+// reports it already serialised as a GraphQL value literal.
+func printDefaultValue(sb *strings.Builder, defaultValue interface{}) {
+	if literal, ok := defaultValue.(string); ok {
+		sb.WriteString(fmt.Sprintf(" = %s", literal))
+	}
+}
+
+// printDeprecated writes the @deprecated directive of any field, argument, input field or enum value.
+func printDeprecated(sb *strings.Builder, isDeprecated bool, deprecationReason interface{}) {
+	if isDeprecated {
+		sb.WriteString(" @deprecated")
+		if reason, ok := deprecationReason.(string); ok && reason != "" {
+			sb.WriteString(fmt.Sprintf("(reason: %s)", quoteString(reason)))
+		}
+	}
+}
+
+func printInterface(sb *strings.Builder, typ introspectionTypeDefinition, options PrintOptions) error {
 	if typ.Kind != ast.Interface {
 		return fmt.Errorf("cannot print %v as %v", typ.Kind, ast.Interface)
 	}
 
-	sb.WriteString(fmt.Sprintf("interface %s {\n", typ.Name))
-	for _, field := range typ.Fields {
-		err := printDescription(sb, typ.Description)
-		if err != nil {
-			return fmt.Errorf("unable to write description for field %s: %w", field.Name, err)
-		}
-		sb.WriteString(fmt.Sprintf("\t%s", field.Name))
-		if len(field.Args) > 0 {
-			sb.WriteString("(\n")
-			for _, arg := range field.Args {
-				sb.WriteString(fmt.Sprintf("\t\t%s: %s\n", arg.Name, introspectionTypeToAstType(arg.Type).String()))
+	sb.WriteString(fmt.Sprintf("interface %s ", typ.Name))
+	if len(typ.Interfaces) > 0 {
+		sb.WriteString("implements ")
+		for i, intface := range typ.Interfaces {
+			sb.WriteString(intface.Name)
+			if i < len(typ.Interfaces)-1 {
+				sb.WriteString(" & ")
 			}
-			sb.WriteString("\t)")
 		}
-		sb.WriteString(fmt.Sprintf(": %s\n", introspectionTypeToAstType(field.Type).String()))
+		sb.WriteString(" ")
+	}
+	sb.WriteString("{\n")
+	if err := printFields(sb, typ, options); err != nil {
+		return err
 	}
 	sb.WriteString("}")
 
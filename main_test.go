@@ -1,12 +1,20 @@
 package gqlfetch
 
 import (
+	"bytes"
+	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
 )
 
 func strPtr(s string) *string { return &s }
@@ -73,6 +81,26 @@ func Test_printInterface(t *testing.T) {
 	): myResponseObject
 }`,
 		},
+		"interface implementing interfaces": {
+			args: args{
+				sb: strings.Builder{},
+				typ: introspectionTypeDefinition{
+					Kind:       ast.Interface,
+					Name:       "MyInterface",
+					Interfaces: []ast.Definition{{Name: "Node"}, {Name: "Entity"}},
+					Fields: []introspectedTypeField{
+						{
+							Name: "id",
+							Type: &introspectedType{
+								Name: strPtr("ID"),
+								Kind: OBJECT,
+							},
+						},
+					},
+				},
+			},
+			expect: "interface MyInterface implements Node & Entity {\n\tid: ID\n}",
+		},
 		"many argument object field": {
 			args: args{
 				sb: strings.Builder{},
@@ -117,7 +145,9 @@ func Test_printInterface(t *testing.T) {
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			printInterface(&tt.args.sb, tt.args.typ)
+			if err := printInterface(&tt.args.sb, tt.args.typ, PrintOptions{}); err != nil {
+				t.Fatalf("printInterface() error = %v", err)
+			}
 			got := tt.args.sb.String()
 			if got != tt.expect {
 				t.Errorf("printing ast.Interface expect: %v got: %v", tt.expect, got)
@@ -165,20 +195,16 @@ func Test_printTypes(t *testing.T) {
 			typ: introspectionTypeDefinition{
 				Kind: ast.Enum,
 				Name: "Status",
-				EnumValues: json.RawMessage(`[
+				EnumValues: []introspectedEnumValue{
 					{
-						"name": "OLD",
-						"description": "",
-						"isDeprecated": true,
-						"deprecationReason": "Use NEW instead"
+						Name:              "OLD",
+						IsDeprecated:      true,
+						DeprecationReason: "Use NEW instead",
 					},
 					{
-						"name": "NEW",
-						"description": "",
-						"isDeprecated": false,
-						"deprecationReason": null
-					}
-				]`),
+						Name: "NEW",
+					},
+				},
 			},
 			expect: `enum Status {
 	OLD @deprecated(reason: "Use NEW instead")
@@ -206,20 +232,129 @@ func Test_printTypes(t *testing.T) {
 	oldField: String @deprecated
 }
 
+`,
+		},
+		"object with argument default value": {
+			typ: introspectionTypeDefinition{
+				Kind: ast.Object,
+				Name: "Query",
+				Fields: []introspectedTypeField{
+					{
+						Name: "users",
+						Type: &introspectedType{
+							Name: strPtr("User"),
+							Kind: OBJECT,
+						},
+						Args: []introspectionInputField{
+							{
+								Name: "first",
+								Type: &introspectedType{
+									Name: strPtr("Int"),
+									Kind: OBJECT,
+								},
+								DefaultValue: "10",
+							},
+						},
+					},
+				},
+			},
+			expect: `type Query {
+	users(
+		first: Int = 10
+	): User
+}
+
+`,
+		},
+		"input object with default values": {
+			typ: introspectionTypeDefinition{
+				Kind: ast.InputObject,
+				Name: "Filter",
+				InputFields: []introspectionInputField{
+					{
+						Name: "order",
+						Type: &introspectedType{
+							Name: strPtr("Order"),
+							Kind: OBJECT,
+						},
+						DefaultValue: "ASC",
+					},
+					{
+						Name: "tags",
+						Type: &introspectedType{
+							Kind:   LIST,
+							OfType: &introspectedType{Name: strPtr("String"), Kind: OBJECT},
+						},
+						DefaultValue: `["a", "b"]`,
+					},
+					{
+						Name: "query",
+						Type: &introspectedType{
+							Name: strPtr("String"),
+							Kind: OBJECT,
+						},
+					},
+				},
+			},
+			expect: `input Filter {
+	order: Order = ASC
+	tags: [String] = ["a", "b"]
+	query: String
+}
+
+`,
+		},
+		"scalar with specification url": {
+			typ: introspectionTypeDefinition{
+				Kind:           ast.Scalar,
+				Name:           "UUID",
+				SpecifiedByURL: strPtr("https://tools.ietf.org/html/rfc4122"),
+			},
+			expect: `scalar UUID @specifiedBy(url: "https://tools.ietf.org/html/rfc4122")
+
+`,
+		},
+		"oneOf input object with deprecated field": {
+			typ: introspectionTypeDefinition{
+				Kind:    ast.InputObject,
+				Name:    "UserBy",
+				IsOneOf: true,
+				InputFields: []introspectionInputField{
+					{
+						Name: "id",
+						Type: &introspectedType{
+							Name: strPtr("ID"),
+							Kind: OBJECT,
+						},
+					},
+					{
+						Name: "login",
+						Type: &introspectedType{
+							Name: strPtr("String"),
+							Kind: OBJECT,
+						},
+						IsDeprecated:      true,
+						DeprecationReason: "Use id instead",
+					},
+				},
+			},
+			expect: `input UserBy @oneOf {
+	id: ID
+	login: String @deprecated(reason: "Use id instead")
+}
+
 `,
 		},
 		"enum with deprecated value without reason": {
 			typ: introspectionTypeDefinition{
 				Kind: ast.Enum,
 				Name: "Status",
-				EnumValues: json.RawMessage(`[
+				EnumValues: []introspectedEnumValue{
 					{
-						"name": "OLD",
-						"description": "",
-						"isDeprecated": true,
-						"deprecationReason": null
-					}
-				]`),
+						Name:         "OLD",
+						IsDeprecated: true,
+					},
+				},
 			},
 			expect: `enum Status {
 	OLD @deprecated
@@ -232,7 +367,7 @@ func Test_printTypes(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			sb := &strings.Builder{}
-			err := printTypes(sb, []introspectionTypeDefinition{tt.typ}, false)
+			err := printTypes(sb, []introspectionTypeDefinition{tt.typ}, false, PrintOptions{})
 			if err != nil {
 				t.Errorf("printTypes() error = %v", err)
 				return
@@ -244,3 +379,693 @@ func Test_printTypes(t *testing.T) {
 		})
 	}
 }
+
+func Test_printDescription(t *testing.T) {
+	tests := map[string]struct {
+		description string
+		expect      string
+	}{
+		"single line": {
+			description: "A user",
+			expect:      "\t\"\"\"\n\tA user\n\t\"\"\"\n",
+		},
+		"multiple lines keep their own indentation": {
+			description: "Usage:\n  query { me }\n\nDone",
+			expect:      "\t\"\"\"\n\tUsage:\n\t  query { me }\n\n\tDone\n\t\"\"\"\n",
+		},
+		"triple quotes and backslashes": {
+			description: `Says """hi""" \o/`,
+			expect:      "\t\"\"\"\n\tSays \\\"\"\"hi\\\"\"\" \\o/\n\t\"\"\"\n",
+		},
+		"leading whitespace": {
+			description: "  indented",
+			expect:      "\t\"  indented\"\n",
+		},
+		"leading blank line": {
+			description: "\nstarts blank",
+			expect:      "\t\"\\nstarts blank\"\n",
+		},
+		"carriage return and control characters": {
+			description: "a\r\nb\x01",
+			expect:      "\t\"a\\r\\nb\\u0001\"\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := printDescription(sb, "\t", tt.description, DescriptionBlock); err != nil {
+				t.Fatalf("printDescription() error = %v", err)
+			}
+			if got := sb.String(); got != tt.expect {
+				t.Errorf("printDescription() = %q, want %q", got, tt.expect)
+			}
+
+			// Every form must read back as the original description.
+			schema := "type Query {\n" + sb.String() + "\tme: String\n}\n"
+			doc, err := parser.ParseSchema(&ast.Source{Input: schema})
+			if err != nil {
+				t.Fatalf("unable to parse %q: %v", schema, err)
+			}
+			if got := doc.Definitions[0].Fields[0].Description; got != tt.description {
+				t.Errorf("parsed description = %q, want %q", got, tt.description)
+			}
+		})
+	}
+}
+
+func Test_printTypesOwnDescriptions(t *testing.T) {
+	types := []introspectionTypeDefinition{
+		{
+			Kind:        ast.Interface,
+			Name:        "Node",
+			Description: "interface",
+			Fields: []introspectedTypeField{{
+				Name:        "id",
+				Description: "interface field",
+				Args: []introspectionInputField{{
+					Name:        "format",
+					Description: "interface arg",
+					Type:        &introspectedType{Name: strPtr("String")},
+				}},
+				Type: &introspectedType{Name: strPtr("ID")},
+			}},
+		},
+		{
+			Kind:        ast.InputObject,
+			Name:        "Filter",
+			Description: "input",
+			InputFields: []introspectionInputField{{
+				Name:        "term",
+				Description: "input field",
+				Type:        &introspectedType{Name: strPtr("String")},
+			}},
+		},
+	}
+
+	sb := &strings.Builder{}
+	if err := printTypes(sb, types, false, PrintOptions{}); err != nil {
+		t.Fatalf("printTypes() error = %v", err)
+	}
+	doc, err := parser.ParseSchema(&ast.Source{Input: sb.String()})
+	if err != nil {
+		t.Fatalf("unable to parse printed types: %v\n%s", err, sb.String())
+	}
+
+	node := doc.Definitions.ForName("Node")
+	if got := node.Fields[0].Description; got != "interface field" {
+		t.Errorf("interface field description = %q", got)
+	}
+	if got := node.Fields[0].Arguments[0].Description; got != "interface arg" {
+		t.Errorf("interface arg description = %q", got)
+	}
+	if got := doc.Definitions.ForName("Filter").Fields[0].Description; got != "input field" {
+		t.Errorf("input field description = %q", got)
+	}
+}
+
+func Test_printTypesDeprecated(t *testing.T) {
+	const reason = "Use \"node\" instead,\nsee C:\\docs"
+	arg := introspectionInputField{
+		Name:              "old",
+		Type:              &introspectedType{Name: strPtr("String")},
+		IsDeprecated:      true,
+		DeprecationReason: reason,
+	}
+	field := introspectedTypeField{
+		Name:              "user",
+		Args:              []introspectionInputField{arg},
+		Type:              &introspectedType{Name: strPtr("String")},
+		IsDeprecated:      true,
+		DeprecationReason: reason,
+	}
+	types := []introspectionTypeDefinition{
+		{Kind: ast.Object, Name: "Query", Fields: []introspectedTypeField{field}},
+		{Kind: ast.Interface, Name: "Node", Fields: []introspectedTypeField{field}},
+		{Kind: ast.InputObject, Name: "Filter", InputFields: []introspectionInputField{arg}},
+		{Kind: ast.Enum, Name: "Role", EnumValues: []introspectedEnumValue{{Name: "USER", IsDeprecated: true, DeprecationReason: reason}}},
+		{Kind: ast.Scalar, Name: "Date", SpecifiedByURL: strPtr(`https://example.com/"date"`)},
+	}
+
+	sb := &strings.Builder{}
+	if err := printTypes(sb, types, false, PrintOptions{}); err != nil {
+		t.Fatalf("printTypes() error = %v", err)
+	}
+	doc, err := parser.ParseSchema(&ast.Source{Input: sb.String()})
+	if err != nil {
+		t.Fatalf("unable to parse printed types: %v\n%s", err, sb.String())
+	}
+
+	reasons := map[string]ast.DirectiveList{
+		"Query.user":     doc.Definitions.ForName("Query").Fields[0].Directives,
+		"Query.user.old": doc.Definitions.ForName("Query").Fields[0].Arguments[0].Directives,
+		"Node.user":      doc.Definitions.ForName("Node").Fields[0].Directives,
+		"Node.user.old":  doc.Definitions.ForName("Node").Fields[0].Arguments[0].Directives,
+		"Filter.old":     doc.Definitions.ForName("Filter").Fields[0].Directives,
+		"Role.USER":      doc.Definitions.ForName("Role").EnumValues[0].Directives,
+	}
+	for path, directives := range reasons {
+		deprecated := directives.ForName("deprecated")
+		if deprecated == nil {
+			t.Errorf("%s is not deprecated", path)
+			continue
+		}
+		if got := deprecated.Arguments.ForName("reason").Value.Raw; got != reason {
+			t.Errorf("%s deprecation reason = %q, want %q", path, got, reason)
+		}
+	}
+
+	url := doc.Definitions.ForName("Date").Directives.ForName("specifiedBy").Arguments.ForName("url").Value.Raw
+	if url != `https://example.com/"date"` {
+		t.Errorf("specifiedBy url = %q", url)
+	}
+}
+
+func Test_introspectionSchemaToAst(t *testing.T) {
+	introspected, err := decodeSchema(strings.NewReader(`{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"mutationType": null,
+		"types": [
+			{"kind": "SCALAR", "name": "String"},
+			{"kind": "SCALAR", "name": "Int"},
+			{"kind": "OBJECT", "name": "Query", "interfaces": [], "fields": [
+				{"name": "users", "args": [
+					{"name": "first", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "10"}
+				], "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}}}
+			]},
+			{"kind": "OBJECT", "name": "User", "interfaces": [], "fields": [
+				{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}, "isDeprecated": true, "deprecationReason": "gone"},
+				{"name": "status", "args": [], "type": {"kind": "ENUM", "name": "Status"}}
+			]},
+			{"kind": "ENUM", "name": "Status", "enumValues": [{"name": "ACTIVE"}, {"name": "BANNED"}]}
+		],
+		"directives": []
+	}}}`))
+	if err != nil {
+		t.Fatalf("unable to decode fixture: %v", err)
+	}
+
+	schema, err := introspectionSchemaToAst(introspected)
+	if err != nil {
+		t.Fatalf("introspectionSchemaToAst() error = %v", err)
+	}
+
+	if schema.Query == nil || schema.Query.Name != "Query" {
+		t.Fatalf("expected Query root, got %v", schema.Query)
+	}
+	users := schema.Query.Fields.ForName("users")
+	if users == nil || users.Type.String() != "[User]!" {
+		t.Fatalf("expected users: [User]!, got %v", users)
+	}
+	if got := users.Arguments.ForName("first").DefaultValue.String(); got != "10" {
+		t.Errorf("expected default value 10, got %s", got)
+	}
+	if schema.Types["User"].Fields.ForName("name").Directives.ForName("deprecated") == nil {
+		t.Errorf("expected User.name to be deprecated")
+	}
+	if got := len(schema.Types["Status"].EnumValues); got != 2 {
+		t.Errorf("expected 2 enum values, got %d", got)
+	}
+}
+
+func TestBuildClientSchemaAST(t *testing.T) {
+	fixture := filepath.Join("testdata", "starwars.json")
+	introspection, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(introspection)
+	}))
+	defer server.Close()
+
+	fromEndpoint, err := BuildClientSchemaAST(context.Background(), BuildClientSchemaOptions{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("BuildClientSchemaAST() error = %v", err)
+	}
+	fromFile, err := BuildClientSchemaASTFromFile(context.Background(), fixture)
+	if err != nil {
+		t.Fatalf("BuildClientSchemaASTFromFile() error = %v", err)
+	}
+
+	for name, schema := range map[string]*ast.Schema{"BuildClientSchemaAST": fromEndpoint, "BuildClientSchemaASTFromFile": fromFile} {
+		if schema.Query == nil || schema.Query.Name != "Query" {
+			t.Errorf("%s: expected Query root, got %v", name, schema.Query)
+		}
+		if schema.Mutation == nil || schema.Mutation.Name != "Mutation" {
+			t.Errorf("%s: expected Mutation root, got %v", name, schema.Mutation)
+		}
+		if hero := schema.Query.Fields.ForName("hero"); hero == nil || hero.Type.String() != "Character" {
+			t.Errorf("%s: expected hero: Character, got %v", name, hero)
+		}
+		if got := len(schema.GetPossibleTypes(schema.Types["Character"])); got != 2 {
+			t.Errorf("%s: expected 2 implementations of Character, got %d", name, got)
+		}
+	}
+
+	if _, err := BuildClientSchemaASTFromFile(context.Background(), filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("BuildClientSchemaASTFromFile() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func Test_printSchemaDefinition(t *testing.T) {
+	tests := map[string]struct {
+		schema introspectionSchema
+		expect string
+	}{
+		"conventional roots": {
+			schema: introspectionSchema{
+				QueryType:        ast.Definition{Name: "Query"},
+				MutationType:     ast.Definition{Name: "Mutation"},
+				SubscriptionType: ast.Definition{Name: "Subscription"},
+			},
+			expect: "",
+		},
+		"renamed roots": {
+			schema: introspectionSchema{
+				QueryType:        ast.Definition{Name: "QueryRoot"},
+				SubscriptionType: ast.Definition{Name: "SubscriptionRoot"},
+			},
+			expect: "schema {\n\tquery: QueryRoot\n\tsubscription: SubscriptionRoot\n}\n\n",
+		},
+		"conventionally named type that is not a root": {
+			schema: introspectionSchema{
+				QueryType: ast.Definition{Name: "Query"},
+				Types:     []introspectionTypeDefinition{{Kind: ast.Object, Name: "Mutation"}},
+			},
+			expect: "schema {\n\tquery: Query\n}\n\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			printSchemaDefinition(sb, tt.schema, PrintOptions{})
+			if got := sb.String(); got != tt.expect {
+				t.Errorf("printSchemaDefinition() = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
+
+func Test_printDirectives(t *testing.T) {
+	sb := &strings.Builder{}
+	err := printDirectives(sb, []introspectionDirectiveDefinition{
+		{
+			Name:         "tag",
+			Locations:    []ast.DirectiveLocation{ast.LocationFieldDefinition, ast.LocationObject},
+			IsRepeatable: true,
+			Args: []introspectionInputField{
+				{
+					Name: "name",
+					Type: &introspectedType{
+						Name: strPtr("String"),
+						Kind: OBJECT,
+					},
+				},
+			},
+		},
+	}, false, PrintOptions{})
+	if err != nil {
+		t.Fatalf("printDirectives() error = %v", err)
+	}
+	expect := "directive @tag(\n\tname: String\n) repeatable on FIELD_DEFINITION | OBJECT\n\n"
+	if got := sb.String(); got != expect {
+		t.Errorf("printDirectives() = %q, want %q", got, expect)
+	}
+}
+
+func Test_buildIntrospectionQuery(t *testing.T) {
+	for version, fields := range map[SpecVersion]map[string]bool{
+		SpecJune2018:      {"isRepeatable": false, "specifiedByURL": false, "isOneOf": false, "includeDeprecated: true) {\n      ...InputValue": false},
+		SpecOctober2021:   {"isRepeatable": true, "specifiedByURL": true, "isOneOf": false},
+		SpecSeptember2025: {"isRepeatable": true, "specifiedByURL": true, "isOneOf": true, "includeDeprecated: true) {\n      ...InputValue": true},
+	} {
+		features, err := version.features()
+		if err != nil {
+			t.Fatalf("%s: features() error = %v", version, err)
+		}
+		query, err := buildIntrospectionQuery(features)
+		if err != nil {
+			t.Fatalf("%s: buildIntrospectionQuery() error = %v", version, err)
+		}
+		for field, expect := range fields {
+			if got := strings.Contains(query, field); got != expect {
+				t.Errorf("%s: query containing %q = %v, want %v", version, field, got, expect)
+			}
+		}
+		if strings.Index(query, "directives {") > strings.Index(query, "types {") {
+			t.Errorf("%s: query asks for types before directives, the printer would hold the directives back", version)
+		}
+	}
+}
+
+func Test_decodeAndPrintSchemaMalformedTypeRef(t *testing.T) {
+	tests := map[string]struct {
+		typ    *introspectedType
+		expect MalformedTypeRefError
+	}{
+		"unknown wrapping kind": {
+			typ:    &introspectedType{Kind: "MAYBE", OfType: &introspectedType{Name: strPtr("String"), Kind: OBJECT}},
+			expect: MalformedTypeRefError{Path: "User.name", Kind: "MAYBE", Reason: "type kind unknown"},
+		},
+		"named type without a name": {
+			typ:    &introspectedType{Kind: NON_NULL, OfType: &introspectedType{Kind: OBJECT}},
+			expect: MalformedTypeRefError{Path: "User.name", Kind: "OBJECT", Reason: "named type without a name"},
+		},
+		"missing type reference": {
+			typ:    nil,
+			expect: MalformedTypeRefError{Path: "User.name", Reason: "missing type reference"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema := introspectionSchema{
+				Types: []introspectionTypeDefinition{
+					{
+						Kind:   ast.Object,
+						Name:   "User",
+						Fields: []introspectedTypeField{{Name: "name", Type: tt.typ}},
+					},
+				},
+			}
+
+			response, err := json.Marshal(map[string]map[string]introspectionSchema{"data": {"__schema": schema}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := decodeAndPrintSchema(bytes.NewReader(response), BuildClientSchemaOptions{})
+			if got != "" {
+				t.Errorf("decodeAndPrintSchema() = %q, want no output", got)
+			}
+			var typeRefErr *MalformedTypeRefError
+			if !errors.As(err, &typeRefErr) {
+				t.Fatalf("decodeAndPrintSchema() error = %v, want *MalformedTypeRefError", err)
+			}
+			if *typeRefErr != tt.expect {
+				t.Errorf("decodeAndPrintSchema() error = %+v, want %+v", *typeRefErr, tt.expect)
+			}
+
+			if _, err := introspectionSchemaToAst(schema); !errors.As(err, &typeRefErr) {
+				t.Errorf("introspectionSchemaToAst() error = %v, want *MalformedTypeRefError", err)
+			}
+		})
+	}
+}
+
+func TestBuildClientSchemaFromFilePrinterFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	err := os.WriteFile(path, []byte(`{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [{"kind": "UNION", "name": "Result", "possibleTypes": [{"kind": "MAYBE", "ofType": {"kind": "OBJECT", "name": "User"}}]}],
+		"directives": []
+	}}}`), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write fixture: %v", err)
+	}
+
+	schema, err := BuildClientSchemaFromFile(context.Background(), path, false)
+	if err == nil {
+		t.Fatalf("BuildClientSchemaFromFile() expected error, got schema %q", schema)
+	}
+	if schema != "" {
+		t.Errorf("BuildClientSchemaFromFile() = %q, want no schema alongside error", schema)
+	}
+}
+
+func TestWriteClientSchema(t *testing.T) {
+	introspection, err := os.ReadFile(filepath.Join("testdata", "starwars.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := os.ReadFile(filepath.Join("testdata", "starwars.graphql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(introspection)
+	}))
+	defer server.Close()
+
+	sb := &strings.Builder{}
+	err = WriteClientSchema(context.Background(), sb, BuildClientSchemaOptions{
+		Endpoint:        server.URL,
+		WithoutBuiltins: true,
+	})
+	if err != nil {
+		t.Fatalf("WriteClientSchema() error = %v", err)
+	}
+	if got := sb.String(); got != string(expect) {
+		t.Errorf("WriteClientSchema() = %q, want %q", got, expect)
+	}
+}
+
+func TestWriteClientSchemaFromFile(t *testing.T) {
+	expect, err := os.ReadFile(filepath.Join("testdata", "starwars.graphql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb := &strings.Builder{}
+	err = WriteClientSchemaFromFile(context.Background(), sb, filepath.Join("testdata", "starwars.json"), true)
+	if err != nil {
+		t.Fatalf("WriteClientSchemaFromFile() error = %v", err)
+	}
+	if got := sb.String(); got != string(expect) {
+		t.Errorf("WriteClientSchemaFromFile() = %q, want %q", got, expect)
+	}
+
+	err = WriteClientSchemaFromFile(context.Background(), &strings.Builder{}, filepath.Join(t.TempDir(), "missing.json"), true)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("WriteClientSchemaFromFile() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func Test_decodeSchemaGraphQLErrors(t *testing.T) {
+	_, err := decodeSchema(strings.NewReader(`{
+		"errors": [{
+			"message": "introspection is disabled",
+			"locations": [{"line": 2, "column": 3}],
+			"path": ["__schema", 0],
+			"extensions": {"code": "INTROSPECTION_DISABLED"}
+		}],
+		"data": {"__schema": {"queryType": {"name": "Query"}, "types": [], "directives": []}}
+	}`))
+
+	var gqlErrs GraphQLErrors
+	if !errors.As(err, &gqlErrs) {
+		t.Fatalf("decodeSchema() error = %v, want GraphQLErrors", err)
+	}
+	if len(gqlErrs) != 1 {
+		t.Fatalf("expected a single error, got %d", len(gqlErrs))
+	}
+	gqlErr := gqlErrs[0]
+	if gqlErr.Code() != "INTROSPECTION_DISABLED" {
+		t.Errorf("Code() = %q, want INTROSPECTION_DISABLED", gqlErr.Code())
+	}
+	if len(gqlErr.Locations) != 1 || gqlErr.Locations[0] != (GraphQLErrorLocation{Line: 2, Column: 3}) {
+		t.Errorf("unexpected locations %v", gqlErr.Locations)
+	}
+	if len(gqlErr.Path) != 2 || gqlErr.Path[0] != "__schema" {
+		t.Errorf("unexpected path %v", gqlErr.Path)
+	}
+	if err.Error() != "encountered the following GraphQL errors: introspection is disabled" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestBuildClientSchemaWithOptionsHTTPError(t *testing.T) {
+	tests := map[string]struct {
+		status      int
+		contentType string
+		body        string
+		expectBody  string
+		expectCode  string
+	}{
+		"plain text body": {
+			status:      http.StatusUnauthorized,
+			contentType: "text/plain",
+			body:        "missing bearer token",
+			expectBody:  "missing bearer token",
+		},
+		"graphql errors body": {
+			status:      http.StatusBadRequest,
+			contentType: "application/graphql-response+json",
+			body:        `{"errors": [{"message": "introspection disabled", "extensions": {"code": "FORBIDDEN"}}]}`,
+			expectBody:  `{"errors": [{"message": "introspection disabled", "extensions": {"code": "FORBIDDEN"}}]}`,
+			expectCode:  "FORBIDDEN",
+		},
+		"oversized body": {
+			status:      http.StatusServiceUnavailable,
+			contentType: "text/html",
+			body:        strings.Repeat("x", maxHTTPErrorBody+10),
+			expectBody:  strings.Repeat("x", maxHTTPErrorBody),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := BuildClientSchemaWithOptions(context.Background(), BuildClientSchemaOptions{
+				Endpoint: server.URL,
+				Method:   http.MethodPost,
+			})
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("BuildClientSchemaWithOptions() error = %v, want *HTTPError", err)
+			}
+			if httpErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", httpErr.StatusCode, tt.status)
+			}
+			if httpErr.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", httpErr.Header.Get("Content-Type"), tt.contentType)
+			}
+			if httpErr.Body != tt.expectBody {
+				t.Errorf("Body = %q, want %q", httpErr.Body, tt.expectBody)
+			}
+
+			var gqlErrs GraphQLErrors
+			if tt.expectCode == "" {
+				if errors.As(err, &gqlErrs) {
+					t.Errorf("unexpected GraphQLErrors %v", gqlErrs)
+				}
+				return
+			}
+			if !errors.As(err, &gqlErrs) || gqlErrs[0].Code() != tt.expectCode {
+				t.Errorf("expected GraphQLErrors with code %s, got %v", tt.expectCode, err)
+			}
+		})
+	}
+}
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestBuildClientSchemaWithOptionsHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"__schema": {"queryType": {"name": "Query"}, "types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [{"name": "ping", "args": [], "type": {"kind": "SCALAR", "name": "String"}}]}
+		], "directives": []}}}`))
+	}))
+	defer server.Close()
+
+	var requests int
+	schema, err := BuildClientSchemaWithOptions(context.Background(), BuildClientSchemaOptions{
+		Endpoint: server.URL,
+		Method:   http.MethodPost,
+		HTTPClient: doerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return server.Client().Do(req)
+		}),
+	})
+	if err != nil {
+		t.Fatalf("BuildClientSchemaWithOptions() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the configured client to send 1 request, sent %d", requests)
+	}
+	if !strings.Contains(schema, "ping: String") {
+		t.Errorf("expected schema to contain ping field, got %s", schema)
+	}
+}
+
+func Test_newQueryRequestGet(t *testing.T) {
+	req, err := newQueryRequest(context.Background(), BuildClientSchemaOptions{
+		Endpoint: "https://example.com/graphql?tenant=acme",
+		Method:   http.MethodGet,
+		Headers:  http.Header{"Authorization": []string{"Bearer token"}},
+	}, "IntrospectionQuery", "query IntrospectionQuery { __schema { types { name } } }")
+	if err != nil {
+		t.Fatalf("newQueryRequest() error = %v", err)
+	}
+
+	if req.Body != nil {
+		t.Errorf("expected GET request without body")
+	}
+	if got := req.Header.Get("Content-Type"); got != "" {
+		t.Errorf("expected no Content-Type header, got %q", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("expected Authorization header to be forwarded, got %q", got)
+	}
+	params := req.URL.Query()
+	for param, expect := range map[string]string{
+		"tenant":        "acme",
+		"operationName": "IntrospectionQuery",
+		"query":         "query IntrospectionQuery { __schema { types { name } } }",
+	} {
+		if got := params.Get(param); got != expect {
+			t.Errorf("query parameter %s = %q, want %q", param, got, expect)
+		}
+	}
+}
+
+func TestBuildClientSchemaWithOptionsContentType(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		body        string
+		expectErr   bool
+	}{
+		"graphql response media type": {
+			contentType: "application/graphql-response+json; charset=utf-8",
+			body:        `{"data": {"__schema": {"queryType": {"name": "Query"}, "types": [], "directives": []}}}`,
+		},
+		"legacy json media type": {
+			contentType: "application/json",
+			body:        `{"data": {"__schema": {"queryType": {"name": "Query"}, "types": [], "directives": []}}}`,
+		},
+		"mislabelled json": {
+			contentType: "text/plain; charset=utf-8",
+			body:        ` {"data": {"__schema": {"queryType": {"name": "Query"}, "types": [], "directives": []}}}`,
+		},
+		"html page": {
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><body>Please sign in</body></html>",
+			expectErr:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if accept := r.Header.Get("Accept"); !strings.HasPrefix(accept, "application/graphql-response+json") {
+					t.Errorf("unexpected Accept header %q", accept)
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := BuildClientSchemaWithOptions(context.Background(), BuildClientSchemaOptions{
+				Endpoint: server.URL,
+				Method:   http.MethodPost,
+			})
+			if !tt.expectErr {
+				if err != nil {
+					t.Errorf("BuildClientSchemaWithOptions() error = %v", err)
+				}
+				return
+			}
+
+			var contentTypeErr *ContentTypeError
+			if !errors.As(err, &contentTypeErr) {
+				t.Fatalf("BuildClientSchemaWithOptions() error = %v, want *ContentTypeError", err)
+			}
+			if contentTypeErr.ContentType != tt.contentType || contentTypeErr.Body != tt.body {
+				t.Errorf("unexpected ContentTypeError %+v", contentTypeErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package gqlfetch
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// schemaPrinter writes the schema as the introspection result streams in. Types go straight to the
+// writer, only the directives are held back when they arrive after the types, as they do in most
+// introspection results; they are written once the last type has been. The introspection query asks
+// for directives first, so servers honouring the field order keep them ahead of the types.
+type schemaPrinter struct {
+	w               io.Writer
+	withoutBuiltins bool
+	options         PrintOptions
+	// roots tracks the root operation types and any types named like implicit roots,
+	// which is all printSchemaDefinition needs to know.
+	roots      introspectionSchema
+	directives strings.Builder
+	// started records whether the schema definition has been considered, after which anything
+	// printed goes straight to the writer.
+	started bool
+	// schemaDone records whether the schema definition went out when the printer started.
+	schemaDone bool
+	// blankLine holds back the blank line following the latest definition when the schema is to
+	// end with a single newline, it is written once another definition follows.
+	blankLine bool
+}
+
+func newSchemaPrinter(w io.Writer, withoutBuiltins bool, options PrintOptions) *schemaPrinter {
+	return &schemaPrinter{w: w, withoutBuiltins: withoutBuiltins, options: options}
+}
+
+func (p *schemaPrinter) handleRoot(operation ast.Operation, name string) error {
+	switch operation {
+	case ast.Query:
+		p.roots.QueryType.Name = name
+	case ast.Mutation:
+		p.roots.MutationType.Name = name
+	case ast.Subscription:
+		p.roots.SubscriptionType.Name = name
+	}
+	return nil
+}
+
+func (p *schemaPrinter) handleDirective(directive introspectionDirectiveDefinition) error {
+	err := printDirectives(&p.directives, []introspectionDirectiveDefinition{directive}, p.withoutBuiltins, p.options)
+	if err != nil {
+		return fmt.Errorf("unable to write directives: %w", err)
+	}
+	return nil
+}
+
+// handleDirectivesEnd writes the directives right away unless types have been written already,
+// in which case finish writes them after the last type.
+func (p *schemaPrinter) handleDirectivesEnd() error {
+	if p.started {
+		return nil
+	}
+	if err := p.start(); err != nil {
+		return err
+	}
+	return p.writeDirectives()
+}
+
+func (p *schemaPrinter) handleType(typ introspectionTypeDefinition) error {
+	if containsStr(typ.Name, []string{"Query", "Mutation", "Subscription"}) {
+		p.roots.Types = append(p.roots.Types, introspectionTypeDefinition{Kind: typ.Kind, Name: typ.Name})
+	}
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	sb := &strings.Builder{}
+	err := printTypes(sb, []introspectionTypeDefinition{typ}, p.withoutBuiltins, p.options)
+	if err != nil {
+		return fmt.Errorf("unable to write types: %w", err)
+	}
+	return p.write(sb.String())
+}
+
+// start writes the schema definition if the roots seen so far call for one.
+func (p *schemaPrinter) start() error {
+	if p.started {
+		return nil
+	}
+	p.started = true
+
+	sb := &strings.Builder{}
+	printSchemaDefinition(sb, p.roots, p.options)
+	p.schemaDone = sb.Len() != 0
+	return p.write(sb.String())
+}
+
+func (p *schemaPrinter) writeDirectives() error {
+	s := p.directives.String()
+	p.directives.Reset()
+	return p.write(s)
+}
+
+// finish writes anything still held back. A schema definition only found to be necessary after
+// the printer started, e.g. due to a later type named Mutation that is not the mutation root,
+// is appended as well.
+func (p *schemaPrinter) finish() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+	if err := p.writeDirectives(); err != nil {
+		return err
+	}
+	if p.schemaDone || !needsSchemaDefinition(p.roots) {
+		return nil
+	}
+	sb := &strings.Builder{}
+	printSchemaDefinition(sb, p.roots, p.options)
+	p.schemaDone = true
+	return p.write(sb.String())
+}
+
+func (p *schemaPrinter) write(s string) error {
+	if s == "" {
+		return nil
+	}
+	if p.options.TrailingNewline {
+		if p.blankLine {
+			s = "\n" + s
+		}
+		p.blankLine = strings.HasSuffix(s, "\n\n")
+		if p.blankLine {
+			s = s[:len(s)-1]
+		}
+	}
+	if _, err := io.WriteString(p.w, s); err != nil {
+		return fmt.Errorf("unable to write schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package gqlfetch
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_schemaPrinter(t *testing.T) {
+	tests := map[string]struct {
+		response string
+		expect   string
+	}{
+		"directives after types follow the types": {
+			response: `{"data": {"__schema": {
+				"queryType": {"name": "Query"},
+				"types": [{"kind": "SCALAR", "name": "Date"}],
+				"directives": [{"name": "auth", "locations": ["OBJECT"], "args": []}]
+			}}}`,
+			expect: "scalar Date\n\ndirective @auth on OBJECT\n\n",
+		},
+		"types after directives": {
+			response: `{"data": {"__schema": {
+				"queryType": {"name": "Query"},
+				"directives": [{"name": "auth", "locations": ["OBJECT"], "args": []}],
+				"types": [{"kind": "SCALAR", "name": "Date"}]
+			}}}`,
+			expect: "directive @auth on OBJECT\n\nscalar Date\n\n",
+		},
+		"implicit root discovered after directives": {
+			response: `{"data": {"__schema": {
+				"queryType": {"name": "Query"},
+				"directives": [],
+				"types": [{"kind": "SCALAR", "name": "Mutation"}]
+			}}}`,
+			expect: "scalar Mutation\n\nschema {\n\tquery: Query\n}\n\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := decodeAndWriteSchema(sb, strings.NewReader(tt.response), BuildClientSchemaOptions{}); err != nil {
+				t.Fatalf("decodeAndWriteSchema() error = %v", err)
+			}
+			if got := sb.String(); got != tt.expect {
+				t.Errorf("decodeAndWriteSchema() = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
+
+// chunkRecorder records every write along with how much of the input had been read at the time,
+// proving types reach the writer as they are decoded.
+type chunkRecorder struct {
+	input   *progressReader
+	chunks  []string
+	offsets []int
+}
+
+func (r *chunkRecorder) Write(p []byte) (int, error) {
+	r.chunks = append(r.chunks, string(p))
+	if r.input != nil {
+		r.offsets = append(r.offsets, r.input.read)
+	}
+	return len(p), nil
+}
+
+type progressReader struct {
+	r    io.Reader
+	read int
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += n
+	return n, err
+}
+
+func Test_schemaPrinterStreamsTypes(t *testing.T) {
+	recorder := &chunkRecorder{}
+	err := decodeAndWriteSchema(recorder, strings.NewReader(`{"data": {"__schema": {
+		"types": [{"kind": "SCALAR", "name": "Date"}, {"kind": "SCALAR", "name": "Time"}],
+		"directives": [{"name": "auth", "locations": ["OBJECT"], "args": []}]
+	}}}`), BuildClientSchemaOptions{})
+	if err != nil {
+		t.Fatalf("decodeAndWriteSchema() error = %v", err)
+	}
+
+	expect := []string{"scalar Date\n\n", "scalar Time\n\n", "directive @auth on OBJECT\n\n"}
+	if strings.Join(recorder.chunks, "|") != strings.Join(expect, "|") {
+		t.Errorf("writes = %q, want %q", recorder.chunks, expect)
+	}
+}
+
+// Test_schemaPrinterStreamsFixture checks the schema is written while a response reporting types
+// before directives, as servers commonly do, is still being read.
+func Test_schemaPrinterStreamsFixture(t *testing.T) {
+	introspection, err := os.ReadFile(filepath.Join("testdata", "starwars.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Index(introspection, []byte(`"types"`)) > bytes.Index(introspection, []byte(`"directives"`)) {
+		t.Fatal("fixture is expected to report types before directives")
+	}
+
+	input := &progressReader{r: bytes.NewReader(introspection)}
+	recorder := &chunkRecorder{input: input}
+	if err := decodeAndWriteSchema(recorder, input, BuildClientSchemaOptions{}); err != nil {
+		t.Fatalf("decodeAndWriteSchema() error = %v", err)
+	}
+
+	if len(recorder.chunks) < 2 {
+		t.Fatalf("expected the schema to be written in several chunks, got %d", len(recorder.chunks))
+	}
+	if recorder.offsets[0] >= len(introspection) {
+		t.Errorf("first write happened after all %d input bytes were read", len(introspection))
+	}
+	if written := recorder.offsets[len(recorder.offsets)/2]; written >= len(introspection) {
+		t.Errorf("half of the writes happened after all %d input bytes were read", len(introspection))
+	}
+}
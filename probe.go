@@ -0,0 +1,108 @@
+package gqlfetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// probeQuery inspects the introspection types themselves, every server supporting introspection
+// answers it regardless of which specification edition it implements.
+const probeQuery = `query IntrospectionCapabilities {
+  directive: __type(name: "__Directive") {
+    ...ProbedType
+  }
+  type: __type(name: "__Type") {
+    ...ProbedType
+  }
+  field: __type(name: "__Field") {
+    ...ProbedType
+  }
+  inputValue: __type(name: "__InputValue") {
+    ...ProbedType
+  }
+}
+
+fragment ProbedType on __Type {
+  fields {
+    name
+    args {
+      name
+    }
+  }
+}
+`
+
+type probeResults struct {
+	Errors GraphQLErrors `json:"errors"`
+	Data   struct {
+		Directive  *probedType `json:"directive"`
+		Type       *probedType `json:"type"`
+		Field      *probedType `json:"field"`
+		InputValue *probedType `json:"inputValue"`
+	} `json:"data"`
+}
+
+type probedType struct {
+	Fields []struct {
+		Name string `json:"name"`
+		Args []struct {
+			Name string `json:"name"`
+		} `json:"args"`
+	} `json:"fields"`
+}
+
+// hasField reports whether the probed type has the field and, optionally, all of the given arguments on it.
+func (t *probedType) hasField(name string, args ...string) bool {
+	if t == nil {
+		return false
+	}
+	for _, field := range t.Fields {
+		if field.Name != name {
+			continue
+		}
+		for _, arg := range args {
+			found := false
+			for _, fieldArg := range field.Args {
+				found = found || fieldArg.Name == arg
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func probeFeatures(ctx context.Context, options BuildClientSchemaOptions) (introspectionFeatures, error) {
+	body, err := executeQuery(ctx, options, "IntrospectionCapabilities", probeQuery)
+	if err != nil {
+		return introspectionFeatures{}, fmt.Errorf("unable to probe introspection capabilities: %w", err)
+	}
+	defer body.Close()
+
+	var probe probeResults
+	if err := json.NewDecoder(body).Decode(&probe); err != nil {
+		return introspectionFeatures{}, fmt.Errorf("unable to decode introspection capabilities: %w", err)
+	}
+	if len(probe.Errors) != 0 {
+		return introspectionFeatures{}, fmt.Errorf("unable to probe introspection capabilities: %w", probe.Errors)
+	}
+
+	return probe.features(), nil
+}
+
+func (p probeResults) features() introspectionFeatures {
+	data := p.Data
+	return introspectionFeatures{
+		DirectiveIsRepeatable: data.Directive.hasField("isRepeatable"),
+		SpecifiedByURL:        data.Type.hasField("specifiedByURL"),
+		InputValueDeprecation: data.InputValue.hasField("isDeprecated") &&
+			data.InputValue.hasField("deprecationReason") &&
+			data.Directive.hasField("args", "includeDeprecated") &&
+			data.Field.hasField("args", "includeDeprecated") &&
+			data.Type.hasField("inputFields", "includeDeprecated"),
+		OneOf: data.Type.hasField("isOneOf"),
+	}
+}
@@ -0,0 +1,65 @@
+package gqlfetch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_probeFeatures(t *testing.T) {
+	var introspected string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unable to decode request: %v", err)
+			return
+		}
+
+		if strings.Contains(body.Query, "IntrospectionCapabilities") {
+			w.Write([]byte(`{"data": {
+				"directive": {"fields": [{"name": "name", "args": []}, {"name": "isRepeatable", "args": []}, {"name": "args", "args": []}]},
+				"type": {"fields": [{"name": "kind", "args": []}, {"name": "specifiedByURL", "args": []}, {"name": "inputFields", "args": []}]},
+				"field": {"fields": [{"name": "args", "args": []}]},
+				"inputValue": {"fields": [{"name": "name", "args": []}]}
+			}}`))
+			return
+		}
+
+		introspected = body.Query
+		w.Write([]byte(`{"data": {"__schema": {"queryType": {"name": "Query"}, "types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [{"name": "ping", "args": [], "type": {"kind": "SCALAR", "name": "String"}}]}
+		], "directives": []}}}`))
+	}))
+	defer server.Close()
+
+	schema, err := BuildClientSchemaWithOptions(context.Background(), BuildClientSchemaOptions{
+		Endpoint: server.URL,
+		Method:   http.MethodPost,
+		Probe:    true,
+		// SpecVersion is ignored when probing, an unknown one must not fail the request.
+		SpecVersion: "Bogus",
+	})
+	if err != nil {
+		t.Fatalf("BuildClientSchemaWithOptions() error = %v", err)
+	}
+	if !strings.Contains(schema, "ping: String") {
+		t.Errorf("expected schema to contain ping field, got %s", schema)
+	}
+
+	for field, expect := range map[string]bool{
+		"isRepeatable":                  true,
+		"specifiedByURL":                true,
+		"isOneOf":                       false,
+		"includeDeprecated":             true, // fields and enum values always request deprecations
+		"args(includeDeprecated: true)": false,
+	} {
+		if got := strings.Contains(introspected, field); got != expect {
+			t.Errorf("introspection query containing %q = %v, want %v", field, got, expect)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package gqlfetch
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// Renderer selects how the schema is printed.
+type Renderer string
+
+const (
+	// RendererPrinter is the default hand-written printer, it streams the schema and honours all
+	// of PrintOptions.
+	RendererPrinter Renderer = "printer"
+	// RendererFormatter converts the introspection result into a validated *ast.Schema and renders
+	// it with gqlparser's formatter, so the output matches gqlgen and other gqlparser based tools.
+	// The formatter orders types and directives by name and only honours PrintOptions.Indent.
+	RendererFormatter Renderer = "formatter"
+)
+
+// renderWithFormatter decodes the whole introspection result and renders it with gqlparser's
+// formatter. WithoutBuiltins leaves out the prelude, otherwise the formatter's builtins, the
+// introspection types included, are rendered too.
+func renderWithFormatter(w io.Writer, schema io.Reader, options BuildClientSchemaOptions) error {
+	collector := &schemaCollector{}
+	sorter := newSortingHandler(collector, options.Sort)
+	if err := streamSchema(schema, sorter); err != nil {
+		return err
+	}
+	if err := sorter.flush(); err != nil {
+		return err
+	}
+
+	validated, err := introspectionSchemaToAst(collector.schema)
+	if err != nil {
+		return err
+	}
+
+	var formatterOptions []formatter.FormatterOption
+	if options.Print.Indent != "" {
+		formatterOptions = append(formatterOptions, formatter.WithIndent(options.Print.Indent))
+	}
+	if !options.WithoutBuiltins {
+		formatterOptions = append(formatterOptions, formatter.WithBuiltin())
+	}
+	sb := &strings.Builder{}
+	formatter.NewFormatter(sb, formatterOptions...).FormatSchema(validated)
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("unable to write schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package gqlfetch
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+func Test_decodeAndPrintSchemaFormatter(t *testing.T) {
+	const sdl = `directive @auth(role: Role = ADMIN) repeatable on FIELD_DEFINITION | OBJECT
+directive @oneOf on INPUT_OBJECT
+
+"The root"
+type Query {
+	"Look up a node"
+	node(id: ID!): Node
+	users(first: Int = 10, filter: Filter): [User!]! @deprecated(reason: "Use \"search\"")
+}
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	role: Role
+}
+
+enum Role {
+	ADMIN
+	USER
+}
+
+input Filter @oneOf {
+	role: Role
+	term: String
+}
+
+scalar Date @specifiedBy(url: "https://example.com/date")
+`
+
+	schema, err := gqlparser.LoadSchema(&ast.Source{Input: sdl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &strings.Builder{}
+	formatter.NewFormatter(expect, formatter.WithIndent("  ")).FormatSchema(schema)
+
+	introspection, err := BuildIntrospectionFromSDL(context.Background(), sdl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeAndPrintSchema(bytes.NewReader(introspection), BuildClientSchemaOptions{
+		WithoutBuiltins: true,
+		Renderer:        RendererFormatter,
+		Print:           PrintOptions{Indent: "  "},
+		Validate:        true,
+	})
+	if err != nil {
+		t.Fatalf("decodeAndPrintSchema() error = %v", err)
+	}
+	if got != expect.String() {
+		t.Errorf("decodeAndPrintSchema() =\n%s\nwant gqlparser's formatting of the source schema:\n%s", got, expect)
+	}
+}
+
+func Test_decodeAndPrintSchemaUnsupportedRenderer(t *testing.T) {
+	_, err := decodeAndPrintSchema(strings.NewReader(`{}`), BuildClientSchemaOptions{Renderer: "handlebars"})
+	if err == nil || err.Error() != "unsupported renderer: handlebars" {
+		t.Errorf("decodeAndPrintSchema() error = %v, want unsupported renderer", err)
+	}
+}
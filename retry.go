@@ -0,0 +1,101 @@
+package gqlfetch
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries schema downloads that fail on connection errors or retryable statuses,
+// backing off exponentially with jitter. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt, values below 2 disable retries.
+	MaxAttempts int
+	// Wait is the backoff before the first retry, doubling for every subsequent one. Defaults to one second.
+	Wait time.Duration
+	// MaxWait caps both the backoff and any Retry-After the server asks for. Defaults to thirty seconds.
+	MaxWait time.Duration
+	// RetryableStatus defaults to 408, 429, 502, 503 and 504.
+	RetryableStatus []int
+}
+
+var defaultRetryableStatus = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+func (p RetryPolicy) retryableStatus(status int) bool {
+	statuses := p.RetryableStatus
+	if statuses == nil {
+		statuses = defaultRetryableStatus
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait after the given failed attempt, counting from one.
+func (p RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	wait, maxWait := p.Wait, p.MaxWait
+	if wait <= 0 {
+		wait = time.Second
+	}
+	if maxWait <= 0 {
+		maxWait = 30 * time.Second
+	}
+
+	if res != nil {
+		if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if retryAfter > maxWait {
+				return maxWait
+			}
+			return retryAfter
+		}
+	}
+
+	for i := 1; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	// Jitter across the upper half of the window, so restarting services are not hit in lockstep.
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// parseRetryAfter understands both forms of the header, delay seconds and an HTTP date.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package gqlfetch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuildClientSchemaWithOptionsRetry(t *testing.T) {
+	tests := map[string]struct {
+		statuses       []int
+		maxAttempts    int
+		expectRequests int
+		expectStatus   int
+	}{
+		"recovers after unavailable": {
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			maxAttempts:    3,
+			expectRequests: 3,
+		},
+		"gives up after max attempts": {
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			maxAttempts:    2,
+			expectRequests: 2,
+			expectStatus:   http.StatusServiceUnavailable,
+		},
+		"does not retry client errors": {
+			statuses:       []int{http.StatusUnauthorized, http.StatusOK},
+			maxAttempts:    3,
+			expectRequests: 1,
+			expectStatus:   http.StatusUnauthorized,
+		},
+		"disabled by default": {
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectRequests: 1,
+			expectStatus:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[requests]
+				requests++
+				if status != http.StatusOK {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(status)
+					return
+				}
+				w.Write([]byte(`{"data": {"__schema": {"queryType": {"name": "Query"}, "types": [], "directives": []}}}`))
+			}))
+			defer server.Close()
+
+			_, err := BuildClientSchemaWithOptions(context.Background(), BuildClientSchemaOptions{
+				Endpoint: server.URL,
+				Method:   http.MethodPost,
+				Retry:    RetryPolicy{MaxAttempts: tt.maxAttempts, Wait: time.Millisecond},
+			})
+
+			if requests != tt.expectRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectRequests, requests)
+			}
+			if tt.expectStatus == 0 {
+				if err != nil {
+					t.Errorf("BuildClientSchemaWithOptions() error = %v", err)
+				}
+				return
+			}
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || httpErr.StatusCode != tt.expectStatus {
+				t.Errorf("BuildClientSchemaWithOptions() error = %v, want status %d", err, tt.expectStatus)
+			}
+		})
+	}
+}
+
+func TestBuildClientSchemaWithOptionsRetryEncodedErrors(t *testing.T) {
+	gzipped := &bytes.Buffer{}
+	gz := gzip.NewWriter(gzipped)
+	gz.Write([]byte(`{"errors": [{"message": "warming up"}]}`))
+	gz.Close()
+
+	tests := map[string]struct {
+		body         []byte
+		expectErrors int
+		expectBody   string
+	}{
+		"empty body":   {},
+		"garbled body": {body: []byte("upstream unavailable"), expectBody: "upstream unavailable"},
+		"encoded body": {body: gzipped.Bytes(), expectErrors: 1, expectBody: `{"errors": [{"message": "warming up"}]}`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Encoding", "gzip")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			_, err := BuildClientSchemaWithOptions(context.Background(), BuildClientSchemaOptions{
+				Endpoint:   server.URL,
+				HTTPClient: &http.Client{Transport: &http.Transport{DisableCompression: true}},
+				Retry:      RetryPolicy{MaxAttempts: 5, Wait: time.Millisecond},
+			})
+
+			if requests != 5 {
+				t.Errorf("expected 5 requests, got %d", requests)
+			}
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("BuildClientSchemaWithOptions() error = %v, want status 503", err)
+			}
+			if httpErr.Body != tt.expectBody {
+				t.Errorf("HTTPError.Body = %q, want %q", httpErr.Body, tt.expectBody)
+			}
+			if len(httpErr.Errors) != tt.expectErrors {
+				t.Errorf("HTTPError.Errors = %v, want %d errors", httpErr.Errors, tt.expectErrors)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{Wait: 100 * time.Millisecond, MaxWait: time.Second}
+
+	for attempt, max := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+	} {
+		got := policy.backoff(attempt, nil)
+		if got < max/2 || got > max {
+			t.Errorf("backoff(%d) = %v, want within [%v, %v]", attempt, got, max/2, max)
+		}
+	}
+
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"120"}}}
+	if got := policy.backoff(1, res); got != time.Second {
+		t.Errorf("backoff with Retry-After beyond MaxWait = %v, want %v", got, time.Second)
+	}
+	res.Header.Set("Retry-After", "0")
+	if got := policy.backoff(3, res); got != 0 {
+		t.Errorf("backoff with Retry-After 0 = %v, want 0", got)
+	}
+}
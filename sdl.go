@@ -0,0 +1,298 @@
+package gqlfetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// BuildIntrospectionFromSDL validates the SDL and returns the introspection response,
+// `{"data": {"__schema": ...}}`, a server of that schema would answer the introspection query with.
+// Types and directives are reported in document order followed by the builtins, builtins the SDL
+// redeclares, as schemas printed with builtins do, are taken from the gqlparser prelude.
+func BuildIntrospectionFromSDL(ctx context.Context, sdl string) ([]byte, error) {
+	doc, err := parser.ParseSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse schema: %w", err)
+	}
+	if doc.Directives.ForName("oneOf") == nil {
+		doc.Directives = append(doc.Directives, oneOfDirective())
+	}
+	schema, err := validateSchemaDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to validate schema: %w", err)
+	}
+
+	introspection, err := astSchemaToIntrospection(ctx, schema, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data struct {
+			Schema introspectionSchema `json:"__schema"`
+		} `json:"data"`
+	}
+	response.Data.Schema = introspection
+	return json.MarshalIndent(response, "", "  ")
+}
+
+// astSchemaToIntrospection converts the validated schema, doc is the parsed document it was
+// validated from and decides the order types and directives are reported in. Conversion stops with
+// ctx's error once ctx is done.
+func astSchemaToIntrospection(ctx context.Context, schema *ast.Schema, doc *ast.SchemaDocument) (introspectionSchema, error) {
+	order, err := documentOrder(doc)
+	if err != nil {
+		return introspectionSchema{}, err
+	}
+	var res introspectionSchema
+	if schema.Query != nil {
+		res.QueryType.Name = schema.Query.Name
+	}
+	if schema.Mutation != nil {
+		res.MutationType.Name = schema.Mutation.Name
+	}
+	if schema.Subscription != nil {
+		res.SubscriptionType.Name = schema.Subscription.Name
+	}
+
+	types := make([]*ast.Definition, 0, len(schema.Types))
+	for _, def := range schema.Types {
+		types = append(types, def)
+	}
+	sort.SliceStable(types, func(i, j int) bool {
+		return order[types[i].Name] < order[types[j].Name]
+	})
+	for _, def := range types {
+		if err := ctx.Err(); err != nil {
+			return introspectionSchema{}, err
+		}
+		typ, err := astDefinitionToIntrospection(schema, def, order)
+		if err != nil {
+			return introspectionSchema{}, err
+		}
+		res.Types = append(res.Types, typ)
+	}
+
+	directives := make([]*ast.DirectiveDefinition, 0, len(schema.Directives))
+	for _, def := range schema.Directives {
+		directives = append(directives, def)
+	}
+	sort.SliceStable(directives, func(i, j int) bool {
+		return order["@"+directives[i].Name] < order["@"+directives[j].Name]
+	})
+	for _, def := range directives {
+		directive := introspectionDirectiveDefinition{
+			Name:         def.Name,
+			Description:  def.Description,
+			Locations:    def.Locations,
+			IsRepeatable: def.IsRepeatable,
+			Args:         []introspectionInputField{},
+		}
+		for _, arg := range def.Arguments {
+			directive.Args = append(directive.Args, astArgumentToIntrospection(schema, arg.Name, arg.Description, arg.Type, arg.DefaultValue, arg.Directives))
+		}
+		res.Directives = append(res.Directives, directive)
+	}
+
+	return res, nil
+}
+
+func astDefinitionToIntrospection(schema *ast.Schema, def *ast.Definition, order map[string]int) (introspectionTypeDefinition, error) {
+	typ := introspectionTypeDefinition{
+		Kind:        def.Kind,
+		Name:        def.Name,
+		Description: def.Description,
+	}
+
+	switch def.Kind {
+	case ast.Object, ast.Interface:
+		typ.Fields = []introspectedTypeField{}
+		typ.Interfaces = []ast.Definition{}
+		for _, field := range def.Fields {
+			// gqlparser adds the meta fields to the query type, introspection leaves them out.
+			if field.Name == "__schema" || field.Name == "__type" || field.Name == "__typename" {
+				continue
+			}
+			reason, deprecated := deprecationReason(field.Directives)
+			fieldDef := introspectedTypeField{
+				Name:              field.Name,
+				Description:       field.Description,
+				Args:              []introspectionInputField{},
+				Type:              astTypeToIntrospection(schema, field.Type),
+				IsDeprecated:      deprecated,
+				DeprecationReason: reason,
+			}
+			for _, arg := range field.Arguments {
+				fieldDef.Args = append(fieldDef.Args, astArgumentToIntrospection(schema, arg.Name, arg.Description, arg.Type, arg.DefaultValue, arg.Directives))
+			}
+			typ.Fields = append(typ.Fields, fieldDef)
+		}
+		for _, name := range def.Interfaces {
+			typ.Interfaces = append(typ.Interfaces, ast.Definition{Kind: ast.Interface, Name: name})
+		}
+		if def.Kind == ast.Interface {
+			typ.PossibleTypes = possibleTypesToIntrospection(schema.GetPossibleTypes(def), order)
+		}
+
+	case ast.Union:
+		members := make([]*ast.Definition, 0, len(def.Types))
+		for _, name := range def.Types {
+			members = append(members, schema.Types[name])
+		}
+		typ.PossibleTypes = possibleTypesToIntrospection(members, order)
+
+	case ast.Enum:
+		typ.EnumValues = []introspectedEnumValue{}
+		for _, value := range def.EnumValues {
+			reason, deprecated := deprecationReason(value.Directives)
+			typ.EnumValues = append(typ.EnumValues, introspectedEnumValue{
+				Name:              value.Name,
+				Description:       value.Description,
+				IsDeprecated:      deprecated,
+				DeprecationReason: reason,
+			})
+		}
+
+	case ast.InputObject:
+		typ.InputFields = []introspectionInputField{}
+		for _, field := range def.Fields {
+			typ.InputFields = append(typ.InputFields, astArgumentToIntrospection(schema, field.Name, field.Description, field.Type, field.DefaultValue, field.Directives))
+		}
+		typ.IsOneOf = def.Directives.ForName("oneOf") != nil
+
+	case ast.Scalar:
+		if specifiedBy := def.Directives.ForName("specifiedBy"); specifiedBy != nil {
+			if url := specifiedBy.Arguments.ForName("url"); url != nil && url.Value != nil {
+				typ.SpecifiedByURL = &url.Value.Raw
+			}
+		}
+
+	default:
+		return introspectionTypeDefinition{}, fmt.Errorf("unsupported type for %s: %s", def.Name, def.Kind)
+	}
+
+	return typ, nil
+}
+
+// astArgumentToIntrospection converts arguments and input fields alike, gqlparser models the
+// latter as field definitions.
+func astArgumentToIntrospection(
+	schema *ast.Schema,
+	name, description string,
+	typ *ast.Type,
+	defaultValue *ast.Value,
+	directives ast.DirectiveList,
+) introspectionInputField {
+	reason, deprecated := deprecationReason(directives)
+	field := introspectionInputField{
+		Name:              name,
+		Description:       description,
+		Type:              astTypeToIntrospection(schema, typ),
+		IsDeprecated:      deprecated,
+		DeprecationReason: reason,
+	}
+	if defaultValue != nil {
+		field.DefaultValue = defaultValue.String()
+	}
+	return field
+}
+
+func astTypeToIntrospection(schema *ast.Schema, typ *ast.Type) *introspectedType {
+	var res *introspectedType
+	if typ.Elem != nil {
+		res = &introspectedType{Kind: LIST, OfType: astTypeToIntrospection(schema, typ.Elem)}
+	} else {
+		name := typ.NamedType
+		res = &introspectedType{Name: &name}
+		if def := schema.Types[name]; def != nil {
+			res.Kind = introspectionTypeKind(def.Kind)
+		}
+	}
+	if typ.NonNull {
+		return &introspectedType{Kind: NON_NULL, OfType: res}
+	}
+	return res
+}
+
+// possibleTypesToIntrospection reports the possible types in document order, gqlparser collects
+// the implementations of an interface from a map.
+func possibleTypesToIntrospection(defs []*ast.Definition, order map[string]int) []*introspectedType {
+	defs = append([]*ast.Definition(nil), defs...)
+	sort.SliceStable(defs, func(i, j int) bool {
+		return order[defs[i].Name] < order[defs[j].Name]
+	})
+
+	res := make([]*introspectedType, 0, len(defs))
+	for _, def := range defs {
+		name := def.Name
+		res = append(res, &introspectedType{Kind: introspectionTypeKind(def.Kind), Name: &name})
+	}
+	return res
+}
+
+// deprecationReason reports the @deprecated reason, falling back to the directive's default.
+func deprecationReason(directives ast.DirectiveList) (interface{}, bool) {
+	deprecated := directives.ForName("deprecated")
+	if deprecated == nil {
+		return nil, false
+	}
+	if reason := deprecated.Arguments.ForName("reason"); reason != nil && reason.Value != nil {
+		return reason.Value.Raw, true
+	}
+	return "No longer supported", true
+}
+
+// documentOrder numbers the types, and the directives prefixed with @, of the document followed by
+// the builtins in prelude order. Builtins keep their place after the user definitions even when the
+// document redeclares them, as printed schemas do, so converting a printed schema reproduces the
+// same order.
+func documentOrder(doc *ast.SchemaDocument) (map[string]int, error) {
+	prelude, err := parser.ParseSchema(validator.Prelude)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse prelude: %w", err)
+	}
+	builtins := make([]string, 0, len(prelude.Definitions)+len(prelude.Directives)+1)
+	for _, def := range prelude.Definitions {
+		builtins = append(builtins, def.Name)
+	}
+	for _, def := range prelude.Directives {
+		builtins = append(builtins, "@"+def.Name)
+	}
+	builtins = append(builtins, "@oneOf")
+	builtin := make(map[string]bool, len(builtins))
+	for _, name := range builtins {
+		builtin[name] = true
+	}
+
+	order := make(map[string]int)
+	add := func(name string) {
+		if _, ok := order[name]; !ok {
+			order[name] = len(order)
+		}
+	}
+	for _, def := range doc.Definitions {
+		if !builtin[def.Name] {
+			add(def.Name)
+		}
+	}
+	for _, def := range doc.Directives {
+		if !builtin["@"+def.Name] {
+			add("@" + def.Name)
+		}
+	}
+	for _, def := range doc.Extensions {
+		if !builtin[def.Name] {
+			add(def.Name)
+		}
+	}
+	for _, name := range builtins {
+		add(name)
+	}
+	return order, nil
+}
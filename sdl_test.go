@@ -0,0 +1,149 @@
+package gqlfetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuildIntrospectionFromSDL(t *testing.T) {
+	const sdl = `"The root"
+type Query {
+	node(id: ID!, first: Int = 10): Node
+	search(term: String = "x"): [Result!]! @deprecated(reason: "use node")
+}
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	name: String @deprecated
+}
+
+union Result = User
+
+enum Role {
+	ADMIN
+	USER @deprecated(reason: "no")
+}
+
+input Filter @oneOf {
+	a: String
+	b: Int
+}
+
+scalar Date @specifiedBy(url: "https://example.com")
+
+directive @auth(role: Role = ADMIN) repeatable on FIELD_DEFINITION
+`
+
+	ctx := context.Background()
+	introspection, err := BuildIntrospectionFromSDL(ctx, sdl)
+	if err != nil {
+		t.Fatalf("BuildIntrospectionFromSDL() error = %v", err)
+	}
+
+	var response struct {
+		Data struct {
+			Schema map[string]json.RawMessage `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(introspection, &response); err != nil {
+		t.Fatalf("unable to decode introspection: %v", err)
+	}
+	for key, expect := range map[string]string{
+		"queryType":        `{"name":"Query"}`,
+		"mutationType":     `null`,
+		"subscriptionType": `null`,
+	} {
+		compact := &bytes.Buffer{}
+		if err := json.Compact(compact, response.Data.Schema[key]); err != nil {
+			t.Fatalf("unable to compact %s: %v", key, err)
+		}
+		if compact.String() != expect {
+			t.Errorf("%s = %s, want %s", key, compact, expect)
+		}
+	}
+
+	schema, err := decodeSchema(bytes.NewReader(introspection))
+	if err != nil {
+		t.Fatalf("decodeSchema() error = %v", err)
+	}
+	var names []string
+	for _, typ := range schema.Types[:7] {
+		names = append(names, typ.Name)
+	}
+	if got, expect := strings.Join(names, " "), "Query Node User Result Role Filter Date"; got != expect {
+		t.Errorf("types = %s, want %s", got, expect)
+	}
+	query := schema.Types[0]
+	if query.Description != "The root" || len(query.Fields) != 2 {
+		t.Fatalf("unexpected Query type: %+v", query)
+	}
+	if query.Fields[0].Args[1].DefaultValue != "10" || query.Fields[1].Args[0].DefaultValue != `"x"` {
+		t.Errorf("unexpected default values: %v, %v", query.Fields[0].Args[1].DefaultValue, query.Fields[1].Args[0].DefaultValue)
+	}
+	if query.Fields[1].DeprecationReason != "use node" {
+		t.Errorf("deprecationReason = %v, want use node", query.Fields[1].DeprecationReason)
+	}
+	if possible := schema.Types[1].PossibleTypes; len(possible) != 1 || *possible[0].Name != "User" || possible[0].Kind != OBJECT {
+		t.Errorf("unexpected possible types of Node: %+v", possible)
+	}
+	if !schema.Types[5].IsOneOf {
+		t.Errorf("Filter is not reported as oneOf")
+	}
+	if url := schema.Types[6].SpecifiedByURL; url == nil || *url != "https://example.com" {
+		t.Errorf("unexpected specifiedByURL: %v", url)
+	}
+
+	var types []map[string]json.RawMessage
+	if err := json.Unmarshal(response.Data.Schema["types"], &types); err != nil {
+		t.Fatalf("unable to decode types: %v", err)
+	}
+	for i, expect := range map[int]string{0: "null", 5: "true", 6: "null"} {
+		if got := string(types[i]["isOneOf"]); got != expect {
+			t.Errorf("isOneOf of %s = %s, want %s", schema.Types[i].Name, got, expect)
+		}
+	}
+
+	// Printing the introspection result and converting it back must not lose anything, whether
+	// or not the printed schema redeclares the builtins.
+	for _, withoutBuiltins := range []bool{true, false} {
+		printed, err := BuildClientSchemaFromReader(ctx, bytes.NewReader(introspection), BuildClientSchemaOptions{WithoutBuiltins: withoutBuiltins})
+		if err != nil {
+			t.Fatalf("BuildClientSchemaFromReader() error = %v", err)
+		}
+		if !withoutBuiltins && !strings.Contains(printed, "scalar Int\n") {
+			t.Fatalf("expected the printed schema to redeclare the builtins, got:\n%s", printed)
+		}
+		roundTrip, err := BuildIntrospectionFromSDL(ctx, printed)
+		if err != nil {
+			t.Fatalf("BuildIntrospectionFromSDL() of printed schema, without builtins %t, error = %v", withoutBuiltins, err)
+		}
+		if !bytes.Equal(introspection, roundTrip) {
+			t.Errorf("round trip, without builtins %t, changed the introspection result, printed schema:\n%s", withoutBuiltins, printed)
+		}
+	}
+}
+
+func TestBuildIntrospectionFromSDLInvalid(t *testing.T) {
+	_, err := BuildIntrospectionFromSDL(context.Background(), "type Query { user: User }")
+	if err == nil || !strings.Contains(err.Error(), "Undefined type User") {
+		t.Errorf("BuildIntrospectionFromSDL() error = %v, want undefined type", err)
+	}
+}
+
+func TestBuildIntrospectionFromSDLCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := BuildIntrospectionFromSDL(ctx, "type Query { ping: String }")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("BuildIntrospectionFromSDL() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,155 @@
+package gqlfetch
+
+import (
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// SortOptions makes the printed schema independent of the order the server reports it in, which
+// keeps diffs of regenerated schema files down to actual changes.
+type SortOptions struct {
+	// Types prints types and directives alphabetically.
+	Types bool
+	// Fields prints fields, input fields, arguments, enum values, union members and implemented
+	// interfaces alphabetically.
+	Fields bool
+	// GroupByKind prints types grouped by kind, in the order the specification introduces them:
+	// scalars, objects, interfaces, unions, enums and input objects.
+	GroupByKind bool
+}
+
+var kindOrder = map[ast.DefinitionKind]int{
+	ast.Scalar:      0,
+	ast.Object:      1,
+	ast.Interface:   2,
+	ast.Union:       3,
+	ast.Enum:        4,
+	ast.InputObject: 5,
+}
+
+// sortingHandler reorders the introspection result on its way to the next handler. Fields are
+// sorted type by type, sorting or grouping types holds back all types and directives until flush.
+type sortingHandler struct {
+	next          introspectionHandler
+	options       SortOptions
+	directives    []introspectionDirectiveDefinition
+	directivesEnd bool
+	types         []introspectionTypeDefinition
+}
+
+func newSortingHandler(next introspectionHandler, options SortOptions) *sortingHandler {
+	return &sortingHandler{next: next, options: options}
+}
+
+func (s *sortingHandler) buffered() bool {
+	return s.options.Types || s.options.GroupByKind
+}
+
+func (s *sortingHandler) handleRoot(operation ast.Operation, name string) error {
+	return s.next.handleRoot(operation, name)
+}
+
+func (s *sortingHandler) handleDirective(directive introspectionDirectiveDefinition) error {
+	if s.options.Fields {
+		sortInputFields(directive.Args)
+	}
+	if s.buffered() {
+		s.directives = append(s.directives, directive)
+		return nil
+	}
+	return s.next.handleDirective(directive)
+}
+
+func (s *sortingHandler) handleDirectivesEnd() error {
+	if s.buffered() {
+		s.directivesEnd = true
+		return nil
+	}
+	return s.next.handleDirectivesEnd()
+}
+
+func (s *sortingHandler) handleType(typ introspectionTypeDefinition) error {
+	if s.options.Fields {
+		sortTypeMembers(typ)
+	}
+	if s.buffered() {
+		s.types = append(s.types, typ)
+		return nil
+	}
+	return s.next.handleType(typ)
+}
+
+// flush hands the held back directives and types to the next handler in order.
+func (s *sortingHandler) flush() error {
+	if !s.buffered() {
+		return nil
+	}
+
+	if s.options.Types {
+		sort.SliceStable(s.directives, func(i, j int) bool {
+			return s.directives[i].Name < s.directives[j].Name
+		})
+	}
+	for _, directive := range s.directives {
+		if err := s.next.handleDirective(directive); err != nil {
+			return err
+		}
+	}
+	if s.directivesEnd {
+		if err := s.next.handleDirectivesEnd(); err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(s.types, func(i, j int) bool {
+		a, b := s.types[i], s.types[j]
+		if s.options.GroupByKind && kindOrder[a.Kind] != kindOrder[b.Kind] {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+		return s.options.Types && a.Name < b.Name
+	})
+	for _, typ := range s.types {
+		if err := s.next.handleType(typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortTypeMembers sorts the members of typ in place.
+func sortTypeMembers(typ introspectionTypeDefinition) {
+	sort.SliceStable(typ.Fields, func(i, j int) bool {
+		return typ.Fields[i].Name < typ.Fields[j].Name
+	})
+	for _, field := range typ.Fields {
+		sortInputFields(field.Args)
+	}
+	sortInputFields(typ.InputFields)
+	sort.SliceStable(typ.EnumValues, func(i, j int) bool {
+		return typ.EnumValues[i].Name < typ.EnumValues[j].Name
+	})
+	sort.SliceStable(typ.Interfaces, func(i, j int) bool {
+		return typ.Interfaces[i].Name < typ.Interfaces[j].Name
+	})
+	sort.SliceStable(typ.PossibleTypes, func(i, j int) bool {
+		return typeRefName(typ.PossibleTypes[i]) < typeRefName(typ.PossibleTypes[j])
+	})
+}
+
+func sortInputFields(fields []introspectionInputField) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+}
+
+// typeRefName returns the name of the named type wrapped by typ.
+func typeRefName(typ *introspectedType) string {
+	for typ != nil {
+		if typ.Name != nil {
+			return *typ.Name
+		}
+		typ = typ.OfType
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package gqlfetch
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_decodeAndPrintSchemaSorted(t *testing.T) {
+	const response = `{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "interfaces": [], "fields": [
+				{"name": "users", "args": [
+					{"name": "last", "type": {"kind": "SCALAR", "name": "Int"}},
+					{"name": "first", "type": {"kind": "SCALAR", "name": "Int"}}
+				], "type": {"kind": "SCALAR", "name": "String"}},
+				{"name": "node", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]},
+			{"kind": "ENUM", "name": "Role", "enumValues": [{"name": "USER"}, {"name": "ADMIN"}]},
+			{"kind": "SCALAR", "name": "Date"},
+			{"kind": "OBJECT", "name": "Account", "interfaces": [], "fields": [
+				{"name": "id", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]}
+		],
+		"directives": [
+			{"name": "log", "locations": ["FIELD"], "args": []},
+			{"name": "auth", "locations": ["FIELD"], "args": []}
+		]
+	}}}`
+
+	tests := map[string]struct {
+		sort   SortOptions
+		expect []string
+	}{
+		"server order": {
+			expect: []string{"type Query", "users(", "last:", "first:", "node:", "enum Role", "USER", "ADMIN", "scalar Date", "type Account", "@log", "@auth"},
+		},
+		"types": {
+			sort:   SortOptions{Types: true},
+			expect: []string{"@auth", "@log", "type Account", "scalar Date", "type Query", "users(", "last:", "first:", "node:", "enum Role", "USER", "ADMIN"},
+		},
+		"fields": {
+			sort:   SortOptions{Fields: true},
+			expect: []string{"type Query", "node:", "users(", "first:", "last:", "enum Role", "ADMIN", "USER", "scalar Date", "type Account", "@log", "@auth"},
+		},
+		"grouped by kind": {
+			sort:   SortOptions{GroupByKind: true},
+			expect: []string{"@log", "@auth", "scalar Date", "type Query", "type Account", "enum Role"},
+		},
+		"everything": {
+			sort:   SortOptions{Types: true, Fields: true, GroupByKind: true},
+			expect: []string{"@auth", "@log", "scalar Date", "type Account", "type Query", "node:", "users(", "first:", "last:", "enum Role", "ADMIN", "USER"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeAndPrintSchema(strings.NewReader(response), BuildClientSchemaOptions{Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("decodeAndPrintSchema() error = %v", err)
+			}
+			rest := got
+			for _, expect := range tt.expect {
+				i := strings.Index(rest, expect)
+				if i < 0 {
+					t.Fatalf("expected %q in order %q, got:\n%s", expect, tt.expect, got)
+				}
+				rest = rest[i+len(expect):]
+			}
+		})
+	}
+}
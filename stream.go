@@ -0,0 +1,234 @@
+package gqlfetch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// introspectionHandler receives the introspection result piece by piece as it is decoded, so only
+// a single type or directive has to be held in memory at any time.
+type introspectionHandler interface {
+	handleRoot(operation ast.Operation, name string) error
+	handleDirective(directive introspectionDirectiveDefinition) error
+	// handleDirectivesEnd is called once all directives have been handled.
+	handleDirectivesEnd() error
+	handleType(typ introspectionTypeDefinition) error
+}
+
+// streamSchema walks the introspection result token by token, handing every root, directive and type
+// to the handler. GraphQL errors are returned once the whole response has been consumed.
+//
+// Besides the full response envelope `{"data": {"__schema": ...}}`, the bare `{"__schema": ...}` and
+// the bare schema object emitted by Apollo and graphql-js tooling are accepted.
+func streamSchema(r io.Reader, handler introspectionHandler) error {
+	dec := json.NewDecoder(r)
+	var gqlErrs GraphQLErrors
+
+	err := streamObject(dec, func(key string) error {
+		switch key {
+		case "errors":
+			return dec.Decode(&gqlErrs)
+		case "data":
+			if len(gqlErrs) != 0 {
+				return skipValue(dec)
+			}
+			return streamObject(dec, func(key string) error {
+				if key != "__schema" {
+					return skipValue(dec)
+				}
+				return streamIntrospectionSchema(dec, handler)
+			})
+		case "__schema":
+			return streamIntrospectionSchema(dec, handler)
+		default:
+			return streamIntrospectionSchemaField(dec, handler, key)
+		}
+	})
+	if err != nil {
+		var handlerErr *streamHandlerError
+		if errors.As(err, &handlerErr) {
+			return handlerErr.err
+		}
+		return fmt.Errorf("unable to decode schema: %w", err)
+	}
+
+	if len(gqlErrs) != 0 {
+		return gqlErrs
+	}
+	return nil
+}
+
+func streamIntrospectionSchema(dec *json.Decoder, handler introspectionHandler) error {
+	return streamObject(dec, func(key string) error {
+		return streamIntrospectionSchemaField(dec, handler, key)
+	})
+}
+
+func streamIntrospectionSchemaField(dec *json.Decoder, handler introspectionHandler, key string) error {
+	switch key {
+	case "queryType", "mutationType", "subscriptionType":
+		var root *struct {
+			Name string `json:"name"`
+		}
+		if err := dec.Decode(&root); err != nil {
+			return err
+		}
+		if root == nil {
+			return nil
+		}
+		operation := map[string]ast.Operation{
+			"queryType":        ast.Query,
+			"mutationType":     ast.Mutation,
+			"subscriptionType": ast.Subscription,
+		}[key]
+		return handlerError(handler.handleRoot(operation, root.Name))
+
+	case "directives":
+		err := streamArray(dec, func() error {
+			var directive introspectionDirectiveDefinition
+			if err := dec.Decode(&directive); err != nil {
+				return err
+			}
+			return handlerError(handler.handleDirective(directive))
+		})
+		if err != nil {
+			return err
+		}
+		return handlerError(handler.handleDirectivesEnd())
+
+	case "types":
+		return streamArray(dec, func() error {
+			var typ introspectionTypeDefinition
+			if err := dec.Decode(&typ); err != nil {
+				return err
+			}
+			return handlerError(handler.handleType(typ))
+		})
+
+	default:
+		return skipValue(dec)
+	}
+}
+
+// streamObject calls field for every key of the next object, which must consume the key's value.
+// A null value is treated as an empty object.
+func streamObject(dec *json.Decoder, field func(key string) error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("expected object, got %v", token)
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := field(token.(string)); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+// streamArray calls element for every element of the next array, which must consume the element.
+// A null value is treated as an empty array.
+func streamArray(dec *json.Decoder, element func() error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected array, got %v", token)
+	}
+
+	for dec.More() {
+		if err := element(); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+// skipValue consumes the next value without retaining it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// streamHandlerError marks errors raised by the handler, as opposed to decoding errors.
+type streamHandlerError struct {
+	err error
+}
+
+func (e *streamHandlerError) Error() string {
+	return e.err.Error()
+}
+
+func handlerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &streamHandlerError{err: err}
+}
+
+// schemaCollector gathers the whole introspection result, for consumers that need it at once.
+type schemaCollector struct {
+	schema introspectionSchema
+}
+
+func (c *schemaCollector) handleRoot(operation ast.Operation, name string) error {
+	switch operation {
+	case ast.Query:
+		c.schema.QueryType.Name = name
+	case ast.Mutation:
+		c.schema.MutationType.Name = name
+	case ast.Subscription:
+		c.schema.SubscriptionType.Name = name
+	}
+	return nil
+}
+
+func (c *schemaCollector) handleDirective(directive introspectionDirectiveDefinition) error {
+	c.schema.Directives = append(c.schema.Directives, directive)
+	return nil
+}
+
+func (c *schemaCollector) handleDirectivesEnd() error {
+	return nil
+}
+
+func (c *schemaCollector) handleType(typ introspectionTypeDefinition) error {
+	c.schema.Types = append(c.schema.Types, typ)
+	return nil
+}
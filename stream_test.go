@@ -0,0 +1,104 @@
+package gqlfetch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_decodeAndPrintSchema(t *testing.T) {
+	tests := map[string]struct {
+		response  string
+		expect    string
+		expectErr string
+	}{
+		"directives after types": {
+			response: `{"data": {"__schema": {
+				"description": "ignored",
+				"queryType": {"name": "Root"},
+				"mutationType": null,
+				"types": [{"kind": "OBJECT", "name": "Root", "interfaces": [], "fields": [
+					{"name": "ping", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+				]}],
+				"directives": [{"name": "auth", "locations": ["FIELD_DEFINITION"], "args": []}]
+			}}, "extensions": {"tracing": {"duration": 12}}}`,
+			expect: "schema {\n\tquery: Root\n}\n\ntype Root {\n\tping: String\n}\n\ndirective @auth on FIELD_DEFINITION\n\n",
+		},
+		"errors after data": {
+			response:  `{"data": {"__schema": {"types": [], "directives": []}}, "errors": [{"message": "partial"}]}`,
+			expectErr: "encountered the following GraphQL errors: partial",
+		},
+		"errors without data": {
+			response:  `{"errors": [{"message": "introspection disabled"}], "data": null}`,
+			expectErr: "encountered the following GraphQL errors: introspection disabled",
+		},
+		"truncated response": {
+			response:  `{"data": {"__schema": {"types": [{"kind": "OBJECT"`,
+			expectErr: "unable to decode schema: unexpected EOF",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeAndPrintSchema(strings.NewReader(tt.response), BuildClientSchemaOptions{})
+			if tt.expectErr != "" {
+				if err == nil || err.Error() != tt.expectErr {
+					t.Errorf("decodeAndPrintSchema() error = %v, want %s", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeAndPrintSchema() error = %v", err)
+			}
+			if got != tt.expect {
+				t.Errorf("decodeAndPrintSchema() = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
+
+func Test_decodeAndPrintSchemaHandlerError(t *testing.T) {
+	_, err := decodeAndPrintSchema(strings.NewReader(`{"data": {"__schema": {"types": [
+		{"kind": "OBJECT", "name": "User", "fields": [{"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR"}}}]}
+	]}}}`), BuildClientSchemaOptions{})
+
+	var typeRefErr *MalformedTypeRefError
+	if !errors.As(err, &typeRefErr) || typeRefErr.Path != "User.id" {
+		t.Errorf("decodeAndPrintSchema() error = %v, want *MalformedTypeRefError at User.id", err)
+	}
+	if strings.HasPrefix(err.Error(), "unable to decode schema") {
+		t.Errorf("printer failures should not be reported as decoding failures: %v", err)
+	}
+}
+
+func Test_decodeAndPrintSchemaShapes(t *testing.T) {
+	const schema = `{"queryType": {"name": "Query"}, "types": [{"kind": "SCALAR", "name": "Date"}], "directives": []}`
+	const expect = "scalar Date\n\n"
+
+	for name, response := range map[string]string{
+		"response envelope": `{"data": {"__schema": ` + schema + `}}`,
+		"bare __schema":     `{"__schema": ` + schema + `}`,
+		"bare schema":       schema,
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := BuildClientSchemaFromReader(context.Background(), strings.NewReader(response), BuildClientSchemaOptions{})
+			if err != nil {
+				t.Fatalf("BuildClientSchemaFromReader() error = %v", err)
+			}
+			if got != expect {
+				t.Errorf("BuildClientSchemaFromReader() = %q, want %q", got, expect)
+			}
+		})
+	}
+}
+
+func TestBuildClientSchemaFromReaderCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := BuildClientSchemaFromReader(ctx, strings.NewReader(`{"data": {"__schema": {"types": []}}}`), BuildClientSchemaOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("BuildClientSchemaFromReader() error = %v, want context.Canceled", err)
+	}
+}
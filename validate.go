@@ -0,0 +1,40 @@
+package gqlfetch
+
+import (
+	"errors"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// loadSDL loads the printed schema the way consumers would, catching printer bugs before the
+// schema is handed out.
+func loadSDL(sdl string) (*ast.Schema, error) {
+	doc, err := parser.ParseSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	if err != nil {
+		return nil, newInvalidSchemaError(err)
+	}
+	// Servers that do not declare @oneOf may still use it.
+	if doc.Directives.ForName("oneOf") == nil {
+		doc.Directives = append(doc.Directives, oneOfDirective())
+	}
+	schema, err := validateSchemaDocument(doc)
+	if err != nil {
+		return nil, newInvalidSchemaError(err)
+	}
+	return schema, nil
+}
+
+func newInvalidSchemaError(err error) *InvalidSchemaError {
+	invalid := &InvalidSchemaError{Message: err.Error(), Err: err}
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		invalid.Message = gqlErr.Message
+		if len(gqlErr.Locations) > 0 {
+			invalid.Line = gqlErr.Locations[0].Line
+			invalid.Column = gqlErr.Locations[0].Column
+		}
+	}
+	return invalid
+}
@@ -0,0 +1,161 @@
+package gqlfetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestGoldenSchemas prints every introspection fixture in testdata with and without builtins,
+// compares the result with its golden .graphql or .builtins.graphql file and checks the printed
+// schema converts back into the same schema. See testdata/README.md on where fixtures come from.
+func TestGoldenSchemas(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures found in testdata")
+	}
+
+	ctx := context.Background()
+	for _, fixture := range fixtures {
+		for _, withoutBuiltins := range []bool{true, false} {
+			fixture, withoutBuiltins := fixture, withoutBuiltins
+			name, golden := strings.TrimSuffix(filepath.Base(fixture), ".json"), strings.TrimSuffix(fixture, ".json")+".graphql"
+			if !withoutBuiltins {
+				name, golden = name+" with builtins", strings.TrimSuffix(fixture, ".json")+".builtins.graphql"
+			}
+			t.Run(name, func(t *testing.T) {
+				introspection, err := os.ReadFile(fixture)
+				if err != nil {
+					t.Fatal(err)
+				}
+				options := BuildClientSchemaOptions{WithoutBuiltins: withoutBuiltins, Validate: true}
+				printed, err := BuildClientSchemaFromReader(ctx, bytes.NewReader(introspection), options)
+				if err != nil {
+					t.Fatalf("BuildClientSchemaFromReader() error = %v", err)
+				}
+
+				if *update {
+					if err := os.WriteFile(golden, []byte(printed), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				expect, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("missing golden file, run go test -update: %v", err)
+				}
+				if printed != string(expect) {
+					t.Errorf("printed schema differs from %s, run go test -update and review the diff:\n%s", golden, printed)
+				}
+
+				roundTrip, err := BuildIntrospectionFromSDL(ctx, printed)
+				if err != nil {
+					t.Fatalf("BuildIntrospectionFromSDL() error = %v", err)
+				}
+				reprinted, err := BuildClientSchemaFromReader(ctx, bytes.NewReader(roundTrip), options)
+				if err != nil {
+					t.Fatalf("BuildClientSchemaFromReader() of round trip error = %v", err)
+				}
+				if got, expect := formatSDL(t, reprinted), formatSDL(t, printed); got != expect {
+					t.Errorf("round trip changed the schema:\n%s\nwant:\n%s", got, expect)
+				}
+			})
+		}
+	}
+}
+
+// formatSDL normalises a printed schema with gqlparser's formatter, which orders definitions by name.
+// Builtins are included since a printed schema may redeclare builtin directives.
+func formatSDL(t *testing.T, sdl string) string {
+	t.Helper()
+	schema, err := loadSDL(sdl)
+	if err != nil {
+		t.Fatalf("loadSDL() error = %v", err)
+	}
+	sb := &strings.Builder{}
+	formatter.NewFormatter(sb, formatter.WithBuiltin()).FormatSchema(schema)
+	return sb.String()
+}
+
+func Test_loadSDL(t *testing.T) {
+	tests := map[string]struct {
+		sdl    string
+		line   int
+		column int
+		expect string
+	}{
+		"valid": {
+			sdl: "type Query {\n\tme: User\n}\n\ntype User implements Node {\n\tid: ID!\n}\n\ninterface Node {\n\tid: ID!\n}\n\ninput Key @oneOf {\n\tid: ID\n}\n",
+		},
+		"syntax error": {
+			sdl:    "type Query {\n\tme: User\n\ntype User {\n\tid: ID!\n}\n",
+			line:   4,
+			column: 6,
+			expect: "printed schema is invalid at line 4, column 6: Expected :, found Name",
+		},
+		"undefined type": {
+			sdl:    "type Query {\n\tme: User\n}\n",
+			line:   2,
+			column: 6,
+			expect: "printed schema is invalid at line 2, column 6: Undefined type User.",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadSDL(tt.sdl)
+			if tt.expect == "" {
+				if err != nil {
+					t.Fatalf("loadSDL() error = %v", err)
+				}
+				return
+			}
+
+			var invalid *InvalidSchemaError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("loadSDL() error = %v, want *InvalidSchemaError", err)
+			}
+			if invalid.Line != tt.line || invalid.Column != tt.column {
+				t.Errorf("location = %d:%d, want %d:%d", invalid.Line, invalid.Column, tt.line, tt.column)
+			}
+			if err.Error() != tt.expect {
+				t.Errorf("loadSDL() error = %q, want %q", err, tt.expect)
+			}
+		})
+	}
+}
+
+func Test_decodeAndPrintSchemaValidate(t *testing.T) {
+	const response = `{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [{"kind": "OBJECT", "name": "Query", "interfaces": [], "fields": [
+			{"name": "me", "args": [], "type": {"kind": "OBJECT", "name": "Missing"}}
+		]}],
+		"directives": []
+	}}}`
+
+	if _, err := decodeAndPrintSchema(strings.NewReader(response), BuildClientSchemaOptions{}); err != nil {
+		t.Fatalf("decodeAndPrintSchema() without Validate error = %v", err)
+	}
+
+	sb := &strings.Builder{}
+	err := decodeAndWriteSchema(sb, strings.NewReader(response), BuildClientSchemaOptions{Validate: true})
+	var invalid *InvalidSchemaError
+	if !errors.As(err, &invalid) || invalid.Line != 2 {
+		t.Fatalf("decodeAndWriteSchema() error = %v, want *InvalidSchemaError on line 2", err)
+	}
+	if sb.Len() != 0 {
+		t.Errorf("invalid schema was written: %q", sb.String())
+	}
+}